@@ -0,0 +1,185 @@
+// Command balancesim plays thousands of seeded games through the game rules without a server,
+// and reports survival, gold, leaks and tower efficiency per wave for balancing.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"realtime-game-backend/internal/game"
+)
+
+func main() {
+	games := flag.Int("games", 1000, "number of games to simulate")
+	seed := flag.Int64("seed", 1, "seed of the first game; game i uses seed+i")
+	mapID := flag.String("map", game.DefaultMapID, "map to play on")
+	mapDir := flag.String("maps", "", "directory of extra map definitions")
+	mode := flag.String("mode", game.LanesMode, "game mode: lanes or maze")
+	runType := flag.String("run", game.CampaignRun, "run type: campaign or endless")
+	waveSetPath := flag.String("waves", "", "wave set JSON file, the built-in campaign if empty")
+	catalogPath := flag.String("catalog", "", "tower catalog JSON file, the built-in catalog if empty")
+	bossSetPath := flag.String("bosses", "", "boss set JSON file, the built-in bosses if empty")
+	maxWaves := flag.Int("max-waves", 0, "waves per game, defaults to the campaign length or 30 for endless runs")
+	loadout := flag.String("towers", "basic,slow,splash,sniper", "comma-separated tower types bought in rotation")
+	placement := flag.String("placement", placePath, "tower placement strategy: path or random")
+	hold := flag.String("hold", holdSmart, "card hold strategy: none, pairs or smart")
+	gold := flag.Int("gold", 100, "starting gold")
+	health := flag.Int("health", 20, "starting base health")
+	format := flag.String("format", "csv", "output format: csv or json")
+	outPath := flag.String("out", "", "output file, stdout if empty")
+	workers := flag.Int("workers", runtime.NumCPU(), "games simulated in parallel")
+	flag.Parse()
+
+	// Load custom game data the same way the server does
+	if *catalogPath != "" {
+		catalog, err := game.LoadTowerCatalog(*catalogPath)
+		if err != nil {
+			log.Fatalf("Failed to load tower catalog: %v", err)
+		}
+		game.SetTowerCatalog(catalog)
+	}
+	var bossSet *game.BossSet
+	if *bossSetPath != "" {
+		var err error
+		if bossSet, err = game.LoadBossSet(*bossSetPath); err != nil {
+			log.Fatalf("Failed to load boss set: %v", err)
+		}
+	}
+	var waveSet *game.WaveSet
+	if *waveSetPath != "" {
+		var err error
+		if waveSet, err = game.LoadWaveSet(*waveSetPath); err != nil {
+			log.Fatalf("Failed to load wave set: %v", err)
+		}
+	}
+	if err := game.SetWaveData(bossSet, waveSet); err != nil {
+		log.Fatalf("Failed to use wave set: %v", err)
+	}
+	if *mapDir != "" {
+		maps, err := game.LoadMaps(*mapDir)
+		if err != nil {
+			log.Fatalf("Failed to load maps: %v", err)
+		}
+		for _, m := range maps {
+			game.RegisterMap(m)
+		}
+	}
+
+	m, err := game.GetMap(*mapID)
+	if err != nil {
+		log.Fatalf("Failed to get map: %v", err)
+	}
+	if err := game.ValidateMode(*mode, m); err != nil {
+		log.Fatalf("Invalid mode: %v", err)
+	}
+	if *runType != game.CampaignRun && *runType != game.EndlessRun {
+		log.Fatalf("Unknown run type %q", *runType)
+	}
+
+	holdStrategy, ok := holdStrategies[*hold]
+	if !ok {
+		log.Fatalf("Unknown hold strategy %q", *hold)
+	}
+
+	var towerTypes []string
+	for _, towerType := range strings.Split(*loadout, ",") {
+		towerType = strings.TrimSpace(towerType)
+		if _, err := game.GetTowerDefinition(towerType); err != nil {
+			log.Fatalf("Invalid loadout: %v", err)
+		}
+		towerTypes = append(towerTypes, towerType)
+	}
+
+	if *maxWaves <= 0 {
+		*maxWaves = game.GetWaveSet().FinalLevel()
+		if *runType == game.EndlessRun {
+			*maxWaves = 30
+		}
+	}
+
+	cfg := config{
+		Map:       m,
+		Mode:      *mode,
+		RunType:   *runType,
+		MaxWaves:  *maxWaves,
+		Loadout:   towerTypes,
+		Placement: *placement,
+		Hold:      holdStrategy,
+		Gold:      *gold,
+		Health:    *health,
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		file, err := os.Create(*outPath)
+		if err != nil {
+			log.Fatalf("Failed to create output file: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := run(cfg, *seed, *games, max(*workers, 1), *format, out); err != nil {
+		log.Fatalf("Simulation failed: %v", err)
+	}
+}
+
+// run plays a batch of games and writes their report to out as csv or json
+func run(cfg config, seed int64, games, workers int, format string, out io.Writer) error {
+	var write func(io.Writer, report) error
+	switch format {
+	case "csv":
+		write = writeCSV
+	case "json":
+		write = writeJSON
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+
+	results, err := playGames(cfg, seed, games, workers)
+	if err != nil {
+		return err
+	}
+
+	if err := write(out, buildReport(results)); err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+	return nil
+}
+
+// playGames plays a batch of games in parallel, game i using seed+i
+func playGames(cfg config, seed int64, games, workers int) ([]gameResult, error) {
+	results := make([]gameResult, games)
+	errs := make([]error, games)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], errs[i] = playGame(cfg, seed+int64(i))
+			}
+		}()
+	}
+
+	for i := 0; i < games; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
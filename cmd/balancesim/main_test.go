@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"realtime-game-backend/internal/game"
+)
+
+// smokeConfig plays a few waves of the campaign on the default map
+func smokeConfig(t *testing.T) config {
+	t.Helper()
+
+	m, err := game.GetMap(game.DefaultMapID)
+	if err != nil {
+		t.Fatalf("getting map: %v", err)
+	}
+	return config{
+		Map:       m,
+		Mode:      game.LanesMode,
+		RunType:   game.CampaignRun,
+		MaxWaves:  3,
+		Loadout:   []string{game.BasicTower, game.SlowTower},
+		Placement: placePath,
+		Hold:      holdStrategies[holdSmart],
+		Gold:      100,
+		Health:    20,
+	}
+}
+
+func TestRunShortCampaign(t *testing.T) {
+	var out bytes.Buffer
+	if err := run(smokeConfig(t), 1, 8, 2, "json", &out); err != nil {
+		t.Fatalf("running simulation: %v", err)
+	}
+
+	var rep report
+	if err := json.Unmarshal(out.Bytes(), &rep); err != nil {
+		t.Fatalf("decoding report: %v", err)
+	}
+	if rep.Games != 8 {
+		t.Fatalf("report covers %d games, want 8", rep.Games)
+	}
+	if len(rep.Waves) == 0 || len(rep.Waves) > 3 {
+		t.Fatalf("report covers %d waves, want 1 to 3", len(rep.Waves))
+	}
+
+	first := rep.Waves[0]
+	if first.Level != 1 || first.Games != 8 {
+		t.Fatalf("every game should reach wave 1, got %+v", first)
+	}
+	if first.AvgGoldStart != 100 || first.AvgSpent <= 0 || first.AvgTowers <= 0 {
+		t.Fatalf("wave 1 didn't spend the starting gold on towers: %+v", first)
+	}
+	if first.SurvivalRate < 0 || first.SurvivalRate > 1 {
+		t.Fatalf("wave 1 survival rate is %v", first.SurvivalRate)
+	}
+
+	built := 0
+	for _, tower := range rep.Towers {
+		built += tower.Built
+	}
+	if built == 0 {
+		t.Fatal("report has no towers built")
+	}
+}
+
+func TestRunRejectsUnknownFormat(t *testing.T) {
+	var out bytes.Buffer
+	if err := run(smokeConfig(t), 1, 1, 1, "xml", &out); err == nil {
+		t.Fatal("accepted an unknown output format")
+	}
+	if out.Len() != 0 {
+		t.Fatal("wrote a report in an unknown format")
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"realtime-game-backend/internal/game"
+	"realtime-game-backend/internal/models"
+)
+
+// Simulation timing, matching the server
+const (
+	tickRate        = 50 * time.Millisecond
+	maxWaveDuration = 10 * time.Minute
+)
+
+// simPlayerID owns every tower in a simulated game
+const simPlayerID = "balancesim"
+
+// config describes the games to simulate
+type config struct {
+	Map       *game.MapDefinition
+	Mode      string
+	RunType   string
+	MaxWaves  int
+	Loadout   []string // Tower types bought in rotation
+	Placement string
+	Hold      func(hand []models.Card)
+	Gold      int
+	Health    int
+}
+
+// waveResult describes how a single game went in one wave
+type waveResult struct {
+	Level     int
+	Survived  bool
+	GoldStart int // Gold before the poker round
+	GoldEnd   int // Gold after the wave's kills were paid
+	HandGold  int
+	BankGold  int
+	KillGold  int
+	Spent     int // Gold spent on towers before the wave
+	Towers    int
+	Leaks     int
+	Damage    int
+	Health    int // Base health after the wave
+}
+
+// gameResult describes a whole simulated game
+type gameResult struct {
+	Seed  int64
+	Waves []waveResult
+
+	// TowerStats and TowerSpent total the combat statistics and gold spent by tower type
+	TowerStats map[string]models.TowerStats
+	TowerSpent map[string]int
+	TowerCount map[string]int
+}
+
+// playGame plays one seeded game: every wave is a poker round, a build phase and the wave itself
+func playGame(cfg config, seed int64) (gameResult, error) {
+	rng := rand.New(rand.NewSource(seed))
+	result := gameResult{
+		Seed:       seed,
+		TowerStats: make(map[string]models.TowerStats),
+		TowerSpent: make(map[string]int),
+		TowerCount: make(map[string]int),
+	}
+
+	spots, err := buildSpots(cfg.Map, cfg.Mode, cfg.Placement, rng)
+	if err != nil {
+		return result, err
+	}
+
+	gold, health := cfg.Gold, cfg.Health
+	var towers []models.Tower
+	next := 0 // Index of the next tower type in the loadout
+
+	for level := 1; level <= cfg.MaxWaves; level++ {
+		wave := waveResult{Level: level, GoldStart: gold}
+
+		// Poker round
+		wave.HandGold = playHand(rng, cfg.Hold)
+		gold += wave.HandGold
+
+		// Build phase: buy towers in loadout order until the next one is too expensive or no spot is left
+		for len(spots) > 0 && len(cfg.Loadout) > 0 {
+			towerType := cfg.Loadout[next%len(cfg.Loadout)]
+			spot, rest, ok := takeSpot(cfg, spots, towers)
+			if !ok {
+				break
+			}
+
+			tower, err := game.CreateTower(simPlayerID, towerType, spot.X, spot.Y)
+			if err != nil {
+				return result, err
+			}
+			if tower.Cost > gold {
+				break
+			}
+
+			tower.ID = fmt.Sprintf("tower-%d", len(towers))
+			towers = append(towers, tower)
+			spots = rest
+			gold -= tower.Cost
+			wave.Spent += tower.Cost
+			result.TowerSpent[tower.Type] += tower.Cost
+			result.TowerCount[tower.Type]++
+			next++
+		}
+		wave.Towers = len(towers)
+
+		// Economy towers pay out when the wave starts
+		for _, bankGold := range game.CalculateBankGold(towers) {
+			wave.BankGold += bankGold
+		}
+		gold += wave.BankGold
+
+		// Fight the wave
+		def := game.WaveDefinitionFor(cfg.RunType, seed, level)
+		var enemyWave models.EnemyWave
+		if cfg.Mode == game.MazeMode {
+			enemyWave, err = game.CreateMazeWave(def, cfg.Map, towers)
+			if err != nil {
+				return result, err
+			}
+		} else {
+			enemyWave = game.CreateEnemyWave(def, cfg.Map)
+		}
+
+		sim := game.NewSimulation(towers, enemyWave)
+		if cfg.Mode == game.MazeMode {
+			sim.Maze = cfg.Map
+		}
+		for !sim.Done() && time.Duration(sim.Elapsed)*time.Millisecond < maxWaveDuration {
+			sim.Tick(tickRate)
+			for _, leak := range sim.DrainLeaks() {
+				wave.Leaks++
+				wave.Damage += leak.Damage
+			}
+		}
+
+		for towerType, stats := range game.SummarizeTowerStatsByType(sim.TowerStats()) {
+			total := result.TowerStats[towerType]
+			total.TowerType = towerType
+			total.Add(stats)
+			result.TowerStats[towerType] = total
+		}
+
+		wave.KillGold = game.CalculateWaveGold(sim.Wave)
+		gold += wave.KillGold
+		health = max(health-wave.Damage, 0)
+
+		wave.GoldEnd = gold
+		wave.Health = health
+		wave.Survived = health > 0
+		result.Waves = append(result.Waves, wave)
+
+		if !wave.Survived {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// takeSpot returns the first spot a tower can be built on and the spots left after it.
+// In maze mode spots that would cut off the base are skipped.
+func takeSpot(cfg config, spots []models.Point, towers []models.Tower) (models.Point, []models.Point, bool) {
+	for i, spot := range spots {
+		if cfg.Mode == game.MazeMode && game.CheckMazePlacement(cfg.Map, towers, spot.X, spot.Y) != nil {
+			continue
+		}
+
+		rest := make([]models.Point, 0, len(spots)-1)
+		rest = append(rest, spots[:i]...)
+		rest = append(rest, spots[i+1:]...)
+		return spot, rest, true
+	}
+	return models.Point{}, spots, false
+}
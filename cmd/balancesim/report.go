@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// waveReport summarizes every game at one wave level
+type waveReport struct {
+	Level              int     `json:"level"`
+	Games              int     `json:"games"`              // Games that reached the wave
+	Survived           int     `json:"survived"`           // Games whose base survived the wave
+	SurvivalRate       float64 `json:"survivalRate"`       // Share of the games reaching the wave that survived it
+	CumulativeSurvival float64 `json:"cumulativeSurvival"` // Share of all games that survived through the wave
+	AvgGoldStart       float64 `json:"avgGoldStart"`
+	AvgGoldEnd         float64 `json:"avgGoldEnd"`
+	AvgHandGold        float64 `json:"avgHandGold"`
+	AvgBankGold        float64 `json:"avgBankGold"`
+	AvgKillGold        float64 `json:"avgKillGold"`
+	AvgSpent           float64 `json:"avgSpent"`
+	AvgTowers          float64 `json:"avgTowers"`
+	AvgLeaks           float64 `json:"avgLeaks"`
+	AvgDamage          float64 `json:"avgDamage"`
+	AvgHealth          float64 `json:"avgHealth"`
+}
+
+// towerReport summarizes a tower type over every game
+type towerReport struct {
+	TowerType     string  `json:"towerType"`
+	Built         int     `json:"built"`
+	GoldSpent     int     `json:"goldSpent"`
+	DamageDealt   int     `json:"damageDealt"`
+	Kills         int     `json:"kills"`
+	ShotsFired    int     `json:"shotsFired"`
+	Overkill      int     `json:"overkill"`
+	Uptime        int64   `json:"uptime"`
+	TowerWaves    int     `json:"towerWaves"`    // Waves fought, summed over every tower of the type
+	DamagePerGold float64 `json:"damagePerGold"` // Damage dealt per gold spent
+	DamagePerWave float64 `json:"damagePerWave"` // Damage dealt per tower per wave
+	KillsPerWave  float64 `json:"killsPerWave"`
+}
+
+// report is the outcome of a batch of simulated games
+type report struct {
+	Games  int           `json:"games"`
+	Waves  []waveReport  `json:"waves"`
+	Towers []towerReport `json:"towers"`
+}
+
+// buildReport aggregates the results of every game
+func buildReport(results []gameResult) report {
+	rep := report{Games: len(results)}
+
+	var waves []waveReport
+	towers := make(map[string]*towerReport)
+	for _, result := range results {
+		for _, wave := range result.Waves {
+			for len(waves) < wave.Level {
+				waves = append(waves, waveReport{Level: len(waves) + 1})
+			}
+
+			w := &waves[wave.Level-1]
+			w.Games++
+			if wave.Survived {
+				w.Survived++
+			}
+			w.AvgGoldStart += float64(wave.GoldStart)
+			w.AvgGoldEnd += float64(wave.GoldEnd)
+			w.AvgHandGold += float64(wave.HandGold)
+			w.AvgBankGold += float64(wave.BankGold)
+			w.AvgKillGold += float64(wave.KillGold)
+			w.AvgSpent += float64(wave.Spent)
+			w.AvgTowers += float64(wave.Towers)
+			w.AvgLeaks += float64(wave.Leaks)
+			w.AvgDamage += float64(wave.Damage)
+			w.AvgHealth += float64(wave.Health)
+		}
+
+		for towerType, stats := range result.TowerStats {
+			t := towerEntry(towers, towerType)
+			t.DamageDealt += stats.DamageDealt
+			t.Kills += stats.Kills
+			t.ShotsFired += stats.ShotsFired
+			t.Overkill += stats.Overkill
+			t.Uptime += stats.Uptime
+			t.TowerWaves += stats.Waves
+		}
+		for towerType, spent := range result.TowerSpent {
+			t := towerEntry(towers, towerType)
+			t.GoldSpent += spent
+			t.Built += result.TowerCount[towerType]
+		}
+	}
+
+	// Turn the sums into averages over the games that reached each wave
+	for i := range waves {
+		w := &waves[i]
+		games := float64(w.Games)
+		w.SurvivalRate = float64(w.Survived) / games
+		w.CumulativeSurvival = float64(w.Survived) / float64(len(results))
+		w.AvgGoldStart /= games
+		w.AvgGoldEnd /= games
+		w.AvgHandGold /= games
+		w.AvgBankGold /= games
+		w.AvgKillGold /= games
+		w.AvgSpent /= games
+		w.AvgTowers /= games
+		w.AvgLeaks /= games
+		w.AvgDamage /= games
+		w.AvgHealth /= games
+	}
+	rep.Waves = waves
+
+	for _, t := range towers {
+		if t.GoldSpent > 0 {
+			t.DamagePerGold = float64(t.DamageDealt) / float64(t.GoldSpent)
+		}
+		if t.TowerWaves > 0 {
+			t.DamagePerWave = float64(t.DamageDealt) / float64(t.TowerWaves)
+			t.KillsPerWave = float64(t.Kills) / float64(t.TowerWaves)
+		}
+		rep.Towers = append(rep.Towers, *t)
+	}
+	sort.Slice(rep.Towers, func(i, j int) bool {
+		return rep.Towers[i].TowerType < rep.Towers[j].TowerType
+	})
+
+	return rep
+}
+
+// towerEntry returns the report of a tower type, creating it if needed
+func towerEntry(towers map[string]*towerReport, towerType string) *towerReport {
+	t, ok := towers[towerType]
+	if !ok {
+		t = &towerReport{TowerType: towerType}
+		towers[towerType] = t
+	}
+	return t
+}
+
+// writeJSON writes the report as indented JSON
+func writeJSON(w io.Writer, rep report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(rep)
+}
+
+// writeCSV writes the wave table, a blank line and the tower table
+func writeCSV(w io.Writer, rep report) error {
+	out := csv.NewWriter(w)
+
+	out.Write([]string{
+		"level", "games", "survived", "survival_rate", "cumulative_survival",
+		"avg_gold_start", "avg_gold_end", "avg_hand_gold", "avg_bank_gold", "avg_kill_gold",
+		"avg_spent", "avg_towers", "avg_leaks", "avg_damage", "avg_health",
+	})
+	for _, wave := range rep.Waves {
+		out.Write([]string{
+			strconv.Itoa(wave.Level), strconv.Itoa(wave.Games), strconv.Itoa(wave.Survived),
+			formatFloat(wave.SurvivalRate), formatFloat(wave.CumulativeSurvival),
+			formatFloat(wave.AvgGoldStart), formatFloat(wave.AvgGoldEnd), formatFloat(wave.AvgHandGold),
+			formatFloat(wave.AvgBankGold), formatFloat(wave.AvgKillGold), formatFloat(wave.AvgSpent),
+			formatFloat(wave.AvgTowers), formatFloat(wave.AvgLeaks), formatFloat(wave.AvgDamage),
+			formatFloat(wave.AvgHealth),
+		})
+	}
+	out.Flush()
+
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return err
+	}
+
+	out.Write([]string{
+		"tower_type", "built", "gold_spent", "damage_dealt", "kills", "shots_fired", "overkill",
+		"uptime_ms", "tower_waves", "damage_per_gold", "damage_per_wave", "kills_per_wave",
+	})
+	for _, t := range rep.Towers {
+		out.Write([]string{
+			t.TowerType, strconv.Itoa(t.Built), strconv.Itoa(t.GoldSpent), strconv.Itoa(t.DamageDealt),
+			strconv.Itoa(t.Kills), strconv.Itoa(t.ShotsFired), strconv.Itoa(t.Overkill),
+			strconv.FormatInt(t.Uptime, 10), strconv.Itoa(t.TowerWaves), formatFloat(t.DamagePerGold),
+			formatFloat(t.DamagePerWave), formatFloat(t.KillsPerWave),
+		})
+	}
+	out.Flush()
+
+	return out.Error()
+}
+
+// formatFloat formats a float for CSV output
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 4, 64)
+}
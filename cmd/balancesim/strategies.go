@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"realtime-game-backend/internal/game"
+	"realtime-game-backend/internal/models"
+)
+
+// Hold strategies decide which cards to keep between draws
+const (
+	holdNone  = "none"  // Redraw every card
+	holdPairs = "pairs" // Keep cards that share a rank
+	holdSmart = "smart" // Keep made hands, four-card flushes, pairs and high cards, in that order
+)
+
+// Placement strategies decide where towers are built
+const (
+	placePath   = "path"   // Closest buildable tiles to the enemy path first
+	placeRandom = "random" // Buildable tiles in a seeded random order
+)
+
+// holdStrategies maps strategy names to functions marking the cards to keep
+var holdStrategies = map[string]func(hand []models.Card){
+	holdNone:  func(hand []models.Card) {},
+	holdPairs: holdMatchingRanks,
+	holdSmart: holdSmartCards,
+}
+
+// holdMatchingRanks holds every card whose rank appears more than once
+func holdMatchingRanks(hand []models.Card) {
+	counts := make(map[string]int)
+	for _, card := range hand {
+		counts[card.Rank]++
+	}
+	for i, card := range hand {
+		hand[i].Held = counts[card.Rank] > 1
+	}
+}
+
+// holdSmartCards holds a made straight or better, then four cards of a suit, then pairs, then jacks or better
+func holdSmartCards(hand []models.Card) {
+	if game.EvaluateHand(hand).Value >= 5 {
+		for i := range hand {
+			hand[i].Held = true
+		}
+		return
+	}
+
+	suits := make(map[string]int)
+	for _, card := range hand {
+		suits[card.Suit]++
+	}
+	for suit, count := range suits {
+		if count >= 4 {
+			for i, card := range hand {
+				hand[i].Held = card.Suit == suit
+			}
+			return
+		}
+	}
+
+	holdMatchingRanks(hand)
+	for _, card := range hand {
+		if card.Held {
+			return
+		}
+	}
+
+	for i, card := range hand {
+		hand[i].Held = card.Value >= 11
+	}
+}
+
+// playHand plays a three-draw poker round and returns the gold paid for the final hand
+func playHand(rng *rand.Rand, hold func(hand []models.Card)) int {
+	deck := game.ShuffleDeckWith(game.NewDeck(), rng)
+	hand, deck := game.DealCards(deck, 5)
+
+	for draw := 1; draw < 3; draw++ {
+		hold(hand)
+		hand, deck = game.DrawCards(hand, deck)
+	}
+
+	return game.GoldForHand(game.EvaluateHand(hand).Value)
+}
+
+// buildSpots returns the tile centers towers can be built on, in the order a placement strategy fills them
+func buildSpots(m *game.MapDefinition, mode, strategy string, rng *rand.Rand) ([]models.Point, error) {
+	var spots []models.Point
+	for y, row := range m.Buildable(mode) {
+		for x, tile := range row {
+			if tile == '.' {
+				spots = append(spots, m.TileCenter(game.TilePoint{X: x, Y: y}))
+			}
+		}
+	}
+
+	switch strategy {
+	case placePath:
+		lanes := m.LanePaths()
+		sort.SliceStable(spots, func(i, j int) bool {
+			return distanceToLanes(spots[i], lanes) < distanceToLanes(spots[j], lanes)
+		})
+	case placeRandom:
+		rng.Shuffle(len(spots), func(i, j int) {
+			spots[i], spots[j] = spots[j], spots[i]
+		})
+	default:
+		return nil, fmt.Errorf("unknown placement strategy %q", strategy)
+	}
+
+	return spots, nil
+}
+
+// distanceToLanes returns the distance from a point to the nearest lane path
+func distanceToLanes(p models.Point, lanes []models.Lane) float64 {
+	best := math.Inf(1)
+	for _, lane := range lanes {
+		for i := 0; i+1 < len(lane.Path); i++ {
+			best = math.Min(best, distanceToSegment(p, lane.Path[i], lane.Path[i+1]))
+		}
+	}
+	return best
+}
+
+// distanceToSegment returns the distance from a point to a line segment
+func distanceToSegment(p, a, b models.Point) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	lengthSquared := dx*dx + dy*dy
+	t := 0.0
+	if lengthSquared > 0 {
+		t = math.Max(0, math.Min(1, ((p.X-a.X)*dx+(p.Y-a.Y)*dy)/lengthSquared))
+	}
+	return math.Hypot(p.X-(a.X+t*dx), p.Y-(a.Y+t*dy))
+}
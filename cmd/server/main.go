@@ -13,6 +13,7 @@ import (
 	"github.com/joho/godotenv"
 
 	"realtime-game-backend/internal/db"
+	"realtime-game-backend/internal/game"
 	"realtime-game-backend/internal/ws"
 )
 
@@ -35,6 +36,61 @@ func main() {
 		cancel()
 	}()
 
+	// Load a custom tower catalog if one is configured, otherwise the built-in catalog is used
+	if path := os.Getenv("TOWER_CATALOG_PATH"); path != "" {
+		catalog, err := game.LoadTowerCatalog(path)
+		if err != nil {
+			log.Fatalf("Failed to load tower catalog: %v", err)
+		}
+		game.SetTowerCatalog(catalog)
+		log.Printf("✅ Loaded %d tower types from %s", len(catalog.Towers), path)
+	}
+
+	// Load custom boss definitions and a custom wave set if configured, otherwise the built-in campaign is used
+	var bossSet *game.BossSet
+	if path := os.Getenv("BOSS_SET_PATH"); path != "" {
+		var err error
+		if bossSet, err = game.LoadBossSet(path); err != nil {
+			log.Fatalf("Failed to load boss set: %v", err)
+		}
+		log.Printf("✅ Loaded %d bosses from %s", len(bossSet.Bosses), path)
+	}
+	var waveSet *game.WaveSet
+	if path := os.Getenv("WAVE_SET_PATH"); path != "" {
+		var err error
+		if waveSet, err = game.LoadWaveSet(path); err != nil {
+			log.Fatalf("Failed to load wave set: %v", err)
+		}
+		log.Printf("✅ Loaded %d waves from %s", len(waveSet.Waves), path)
+	}
+
+	// Both sets are in place before the waves' bosses are checked
+	if err := game.SetWaveData(bossSet, waveSet); err != nil {
+		log.Fatalf("Failed to use wave set: %v", err)
+	}
+
+	// Load custom adaptive difficulty bounds if configured
+	if path := os.Getenv("DIRECTOR_CONFIG_PATH"); path != "" {
+		directorConfig, err := game.LoadDirectorConfig(path)
+		if err != nil {
+			log.Fatalf("Failed to load director config: %v", err)
+		}
+		game.SetDirectorConfig(directorConfig)
+		log.Printf("✅ Loaded adaptive difficulty bounds from %s", path)
+	}
+
+	// Register extra maps if a map directory is configured
+	if dir := os.Getenv("MAP_DIR"); dir != "" {
+		maps, err := game.LoadMaps(dir)
+		if err != nil {
+			log.Fatalf("Failed to load maps: %v", err)
+		}
+		for _, m := range maps {
+			game.RegisterMap(m)
+		}
+		log.Printf("✅ Loaded %d maps from %s", len(maps), dir)
+	}
+
 	// Initialize database connections
 	postgresDB, err := db.NewPostgresDB(ctx)
 	if err != nil {
@@ -54,7 +110,26 @@ func main() {
 	defer redisDB.Close()
 
 	// Create WebSocket hub
-	hub := ws.NewHub()
+	hub := ws.NewHub(postgresDB, redisDB)
+
+	// Optional per-phase countdowns, e.g. PHASE_TIMERS=cards=30s,towers=45s
+	if spec := os.Getenv("PHASE_TIMERS"); spec != "" {
+		timers, err := ws.ParsePhaseTimers(spec)
+		if err != nil {
+			log.Fatalf("Failed to parse phase timers: %v", err)
+		}
+		hub.PhaseTimers = timers
+		log.Printf("✅ Loaded %d phase timers", len(timers))
+	}
+
+	// How long disconnected players can resume their game, e.g. RESUME_GRACE_PERIOD=5m
+	if value := os.Getenv("RESUME_GRACE_PERIOD"); value != "" {
+		grace, err := time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("Failed to parse resume grace period: %v", err)
+		}
+		hub.ResumeGrace = grace
+	}
 	go hub.Run(ctx)
 
 	// Set up HTTP routes
@@ -69,6 +144,26 @@ func main() {
 		w.Write([]byte("OK"))
 	})
 
+	// Tower catalog endpoint so clients can render every tower type
+	mux.HandleFunc("/api/towers", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(w).Encode(game.GetTowerCatalog()); err != nil {
+			log.Printf("Error encoding tower catalog: %v", err)
+		}
+	})
+
+	// Map registry endpoint so clients can list and preview maps
+	mux.HandleFunc("/api/maps", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(w).Encode(game.ListMaps()); err != nil {
+			log.Printf("Error encoding maps: %v", err)
+		}
+	})
+
 	// High scores API endpoints
 	mux.HandleFunc("/api/highscores", func(w http.ResponseWriter, r *http.Request) {
 		// Set CORS headers to allow requests from any origin
@@ -85,7 +180,12 @@ func main() {
 
 		// Handle GET request to retrieve high scores
 		if r.Method == "GET" {
-			highScores, err := postgresDB.GetHighScores(r.Context(), 10)
+			getHighScores := postgresDB.GetHighScores
+			if r.URL.Query().Get("category") == db.EndlessCategory {
+				getHighScores = postgresDB.GetEndlessHighScores
+			}
+
+			highScores, err := getHighScores(r.Context(), 10)
 			if err != nil {
 				log.Printf("Error getting high scores: %v", err)
 				http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -107,8 +207,11 @@ func main() {
 		if r.Method == "POST" {
 			// Parse request body
 			var scoreData struct {
-				Name  string `json:"name"`
-				Score int    `json:"score"`
+				Name     string `json:"name"`
+				Score    int    `json:"score"`
+				Category string `json:"category"` // "campaign" (default) or "endless"
+				Wave     int    `json:"wave"`     // Highest wave reached, for endless runs
+				Assisted bool   `json:"assisted"` // The adaptive difficulty director made the run easier
 			}
 
 			if err := json.NewDecoder(r.Body).Decode(&scoreData); err != nil {
@@ -122,8 +225,22 @@ func main() {
 				return
 			}
 
-			// Save high score
-			isHighScore, err := postgresDB.SaveHighScore(r.Context(), scoreData.Name, scoreData.Score)
+			// Save high score in its leaderboard category
+			var isHighScore bool
+			var err error
+			switch scoreData.Category {
+			case "", db.CampaignCategory:
+				isHighScore, err = postgresDB.SaveHighScore(r.Context(), scoreData.Name, scoreData.Score, scoreData.Assisted)
+			case db.EndlessCategory:
+				if scoreData.Wave <= 0 {
+					http.Error(w, "Invalid wave", http.StatusBadRequest)
+					return
+				}
+				isHighScore, err = postgresDB.SaveEndlessHighScore(r.Context(), scoreData.Name, scoreData.Wave, scoreData.Score, scoreData.Assisted)
+			default:
+				http.Error(w, "Invalid category", http.StatusBadRequest)
+				return
+			}
 			if err != nil {
 				log.Printf("Error saving high score: %v", err)
 				http.Error(w, "Internal server error", http.StatusInternalServerError)
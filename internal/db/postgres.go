@@ -2,16 +2,26 @@ package db
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"log"
 	"os"
 
 	"github.com/jackc/pgx/v5"
+
+	"realtime-game-backend/internal/models"
 )
 
 // Error definitions
 var (
 	ErrMissingConnectionString = errors.New("missing database connection string")
+	ErrSavedGameNotFound       = errors.New("saved game not found")
+)
+
+// High score leaderboard categories
+const (
+	CampaignCategory = "campaign"
+	EndlessCategory  = "endless"
 )
 
 // PostgresDB represents a PostgreSQL database connection
@@ -129,6 +139,98 @@ func (db *PostgresDB) InitSchema(ctx context.Context) error {
 		return err
 	}
 
+	// Separate leaderboard categories; endless runs are ranked by the highest wave reached
+	_, err = db.conn.Exec(ctx, `
+		ALTER TABLE high_scores
+			ADD COLUMN IF NOT EXISTS category VARCHAR(20) NOT NULL DEFAULT 'campaign',
+			ADD COLUMN IF NOT EXISTS wave INTEGER NOT NULL DEFAULT 0
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.conn.Exec(ctx, `
+		CREATE INDEX IF NOT EXISTS idx_high_scores_endless ON high_scores (category, wave DESC, score DESC)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Runs helped by the adaptive difficulty director are flagged on their session and high scores
+	_, err = db.conn.Exec(ctx, `
+		ALTER TABLE game_sessions
+			ADD COLUMN IF NOT EXISTS assisted BOOLEAN NOT NULL DEFAULT FALSE
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.conn.Exec(ctx, `
+		ALTER TABLE high_scores
+			ADD COLUMN IF NOT EXISTS assisted BOOLEAN NOT NULL DEFAULT FALSE
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create difficulty_adjustments table logging every change made by the adaptive director
+	_, err = db.conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS difficulty_adjustments (
+			id SERIAL PRIMARY KEY,
+			session_id VARCHAR(36) NOT NULL REFERENCES game_sessions(id),
+			player_id VARCHAR(36) NOT NULL,
+			wave INTEGER NOT NULL,
+			pressure DOUBLE PRECISION NOT NULL,
+			health_multiplier DOUBLE PRECISION NOT NULL,
+			count_multiplier DOUBLE PRECISION NOT NULL,
+			assisted BOOLEAN NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create tower_type_stats table for balancing tower types
+	_, err = db.conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS tower_type_stats (
+			tower_type VARCHAR(50) PRIMARY KEY,
+			tower_waves INTEGER NOT NULL DEFAULT 0,
+			damage_dealt BIGINT NOT NULL DEFAULT 0,
+			kills BIGINT NOT NULL DEFAULT 0,
+			shots_fired BIGINT NOT NULL DEFAULT 0,
+			overkill BIGINT NOT NULL DEFAULT 0,
+			uptime_ms BIGINT NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create saved_games table holding snapshots of runs players can resume later
+	_, err = db.conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS saved_games (
+			id VARCHAR(36) PRIMARY KEY,
+			player_id VARCHAR(36) NOT NULL,
+			run_type VARCHAR(20) NOT NULL,
+			wave INTEGER NOT NULL,
+			state JSONB NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.conn.Exec(ctx, `
+		CREATE INDEX IF NOT EXISTS saved_games_player_idx ON saved_games (player_id, created_at DESC)
+	`)
+	if err != nil {
+		return err
+	}
+
 	log.Println("✅ Database schema initialized")
 	return nil
 }
@@ -304,11 +406,12 @@ func (db *PostgresDB) GetHighScores(ctx context.Context, limit int) ([]map[strin
 	}
 
 	rows, err := db.conn.Query(ctx, `
-		SELECT player_name, score, created_at::text
+		SELECT player_name, score, assisted, created_at::text
 		FROM high_scores
+		WHERE category = $2
 		ORDER BY score DESC
 		LIMIT $1
-	`, limit)
+	`, limit, CampaignCategory)
 	if err != nil {
 		return nil, err
 	}
@@ -318,15 +421,17 @@ func (db *PostgresDB) GetHighScores(ctx context.Context, limit int) ([]map[strin
 	for rows.Next() {
 		var playerName string
 		var score int
+		var assisted bool
 		var createdAt string
 
-		if err := rows.Scan(&playerName, &score, &createdAt); err != nil {
+		if err := rows.Scan(&playerName, &score, &assisted, &createdAt); err != nil {
 			return nil, err
 		}
 
 		highScore := map[string]interface{}{
 			"name":       playerName,
 			"score":      score,
+			"assisted":   assisted,
 			"created_at": createdAt,
 		}
 		highScores = append(highScores, highScore)
@@ -339,15 +444,16 @@ func (db *PostgresDB) GetHighScores(ctx context.Context, limit int) ([]map[strin
 	return highScores, nil
 }
 
-// SaveHighScore saves a high score to the database and returns whether it's a top score
-func (db *PostgresDB) SaveHighScore(ctx context.Context, playerName string, score int) (bool, error) {
+// SaveHighScore saves a high score to the database and returns whether it's a top score.
+// Assisted scores come from runs the adaptive difficulty director made easier.
+func (db *PostgresDB) SaveHighScore(ctx context.Context, playerName string, score int, assisted bool) (bool, error) {
 	// Check if this score is in the top 10
 	var lowestTopScore int
 	var count int
 
 	err := db.conn.QueryRow(ctx, `
-		SELECT COUNT(*) FROM high_scores
-	`).Scan(&count)
+		SELECT COUNT(*) FROM high_scores WHERE category = $1
+	`, CampaignCategory).Scan(&count)
 	if err != nil {
 		return false, err
 	}
@@ -362,10 +468,11 @@ func (db *PostgresDB) SaveHighScore(ctx context.Context, playerName string, scor
 		err = db.conn.QueryRow(ctx, `
 			SELECT MIN(score) FROM (
 				SELECT score FROM high_scores
+				WHERE category = $1
 				ORDER BY score DESC
 				LIMIT 10
 			) AS top_scores
-		`).Scan(&lowestTopScore)
+		`, CampaignCategory).Scan(&lowestTopScore)
 		if err != nil {
 			return false, err
 		}
@@ -376,9 +483,9 @@ func (db *PostgresDB) SaveHighScore(ctx context.Context, playerName string, scor
 	if isHighScore {
 		// Insert the new high score
 		_, err = db.conn.Exec(ctx, `
-			INSERT INTO high_scores (player_name, score)
-			VALUES ($1, $2)
-		`, playerName, score)
+			INSERT INTO high_scores (player_name, score, category, assisted)
+			VALUES ($1, $2, $3, $4)
+		`, playerName, score, CampaignCategory, assisted)
 		if err != nil {
 			return false, err
 		}
@@ -389,10 +496,119 @@ func (db *PostgresDB) SaveHighScore(ctx context.Context, playerName string, scor
 				DELETE FROM high_scores
 				WHERE id IN (
 					SELECT id FROM high_scores
+					WHERE category = $1
 					ORDER BY score ASC
-					LIMIT (SELECT COUNT(*) - 10 FROM high_scores)
+					LIMIT (SELECT COUNT(*) - 10 FROM high_scores WHERE category = $1)
+				)
+			`, CampaignCategory)
+			if err != nil {
+				return false, err
+			}
+		}
+	}
+
+	return isHighScore, nil
+}
+
+// GetEndlessHighScores retrieves the top endless runs, ranked by highest wave reached and then score
+func (db *PostgresDB) GetEndlessHighScores(ctx context.Context, limit int) ([]map[string]interface{}, error) {
+	if limit <= 0 {
+		limit = 10 // Default to top 10 if not specified
+	}
+
+	rows, err := db.conn.Query(ctx, `
+		SELECT player_name, wave, score, assisted, created_at::text
+		FROM high_scores
+		WHERE category = $2
+		ORDER BY wave DESC, score DESC
+		LIMIT $1
+	`, limit, EndlessCategory)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var highScores []map[string]interface{}
+	for rows.Next() {
+		var playerName string
+		var wave, score int
+		var assisted bool
+		var createdAt string
+
+		if err := rows.Scan(&playerName, &wave, &score, &assisted, &createdAt); err != nil {
+			return nil, err
+		}
+
+		highScore := map[string]interface{}{
+			"name":       playerName,
+			"wave":       wave,
+			"score":      score,
+			"assisted":   assisted,
+			"created_at": createdAt,
+		}
+		highScores = append(highScores, highScore)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return highScores, nil
+}
+
+// SaveEndlessHighScore saves an endless run to the database and returns whether it's a top run
+func (db *PostgresDB) SaveEndlessHighScore(ctx context.Context, playerName string, wave, score int, assisted bool) (bool, error) {
+	// Check if this run is in the top 10
+	var count int
+	err := db.conn.QueryRow(ctx, `
+		SELECT COUNT(*) FROM high_scores WHERE category = $1
+	`, EndlessCategory).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	isHighScore := count < 10
+	if !isHighScore {
+		// Compare against the lowest top 10 run, by wave and then score
+		var lowestWave, lowestScore int
+		err = db.conn.QueryRow(ctx, `
+			SELECT wave, score FROM (
+				SELECT wave, score FROM high_scores
+				WHERE category = $1
+				ORDER BY wave DESC, score DESC
+				LIMIT 10
+			) AS top_runs
+			ORDER BY wave ASC, score ASC
+			LIMIT 1
+		`, EndlessCategory).Scan(&lowestWave, &lowestScore)
+		if err != nil {
+			return false, err
+		}
+
+		isHighScore = wave > lowestWave || (wave == lowestWave && score > lowestScore)
+	}
+
+	if isHighScore {
+		// Insert the new run
+		_, err = db.conn.Exec(ctx, `
+			INSERT INTO high_scores (player_name, score, category, wave, assisted)
+			VALUES ($1, $2, $3, $4, $5)
+		`, playerName, score, EndlessCategory, wave, assisted)
+		if err != nil {
+			return false, err
+		}
+
+		// If we have more than 10 runs, delete the lowest ones
+		if count >= 10 {
+			_, err = db.conn.Exec(ctx, `
+				DELETE FROM high_scores
+				WHERE id IN (
+					SELECT id FROM high_scores
+					WHERE category = $1
+					ORDER BY wave ASC, score ASC
+					LIMIT (SELECT COUNT(*) - 10 FROM high_scores WHERE category = $1)
 				)
-			`)
+			`, EndlessCategory)
 			if err != nil {
 				return false, err
 			}
@@ -401,3 +617,114 @@ func (db *PostgresDB) SaveHighScore(ctx context.Context, playerName string, scor
 
 	return isHighScore, nil
 }
+
+// RecordDifficultyAdjustment logs a change made by the adaptive difficulty director in a game session.
+// Sessions with an adjustment that made a wave easier are flagged as assisted.
+func (db *PostgresDB) RecordDifficultyAdjustment(ctx context.Context, sessionID string, adjustment models.DifficultyAdjustment) error {
+	_, err := db.conn.Exec(ctx, `
+		INSERT INTO difficulty_adjustments (session_id, player_id, wave, pressure, health_multiplier, count_multiplier, assisted)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, sessionID, adjustment.PlayerID, adjustment.Level, adjustment.Pressure, adjustment.HealthMultiplier, adjustment.CountMultiplier, adjustment.Assisted)
+	if err != nil {
+		return err
+	}
+
+	if adjustment.Assisted {
+		_, err = db.conn.Exec(ctx, `
+			UPDATE game_sessions
+			SET assisted = TRUE, updated_at = NOW()
+			WHERE id = $1
+		`, sessionID)
+	}
+
+	return err
+}
+
+// RecordTowerTypeStats adds a wave's per-tower-type combat statistics to the running totals
+func (db *PostgresDB) RecordTowerTypeStats(ctx context.Context, stats map[string]models.TowerStats) error {
+	for towerType, total := range stats {
+		_, err := db.conn.Exec(ctx, `
+			INSERT INTO tower_type_stats (tower_type, tower_waves, damage_dealt, kills, shots_fired, overkill, uptime_ms)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (tower_type) DO UPDATE SET
+				tower_waves = tower_type_stats.tower_waves + EXCLUDED.tower_waves,
+				damage_dealt = tower_type_stats.damage_dealt + EXCLUDED.damage_dealt,
+				kills = tower_type_stats.kills + EXCLUDED.kills,
+				shots_fired = tower_type_stats.shots_fired + EXCLUDED.shots_fired,
+				overkill = tower_type_stats.overkill + EXCLUDED.overkill,
+				uptime_ms = tower_type_stats.uptime_ms + EXCLUDED.uptime_ms,
+				updated_at = NOW()
+		`, towerType, total.Waves, total.DamageDealt, total.Kills, total.ShotsFired, total.Overkill, total.Uptime)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SaveGame stores a snapshot of a player's game
+func (db *PostgresDB) SaveGame(ctx context.Context, id, playerID string, state models.GameState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.conn.Exec(ctx, `
+		INSERT INTO saved_games (id, player_id, run_type, wave, state)
+		VALUES ($1, $2, $3, $4, $5)
+	`, id, playerID, state.RunType, state.WaveLevel, data)
+	return err
+}
+
+// LoadGame retrieves a game saved by a player
+func (db *PostgresDB) LoadGame(ctx context.Context, id, playerID string) (*models.GameState, error) {
+	var data []byte
+	err := db.conn.QueryRow(ctx, `
+		SELECT state
+		FROM saved_games
+		WHERE id = $1 AND player_id = $2
+	`, id, playerID).Scan(&data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSavedGameNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state models.GameState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// ListSavedGames retrieves a player's saved games, most recent first
+func (db *PostgresDB) ListSavedGames(ctx context.Context, playerID string) ([]models.SavedGame, error) {
+	rows, err := db.conn.Query(ctx, `
+		SELECT id, player_id, run_type, wave, created_at
+		FROM saved_games
+		WHERE player_id = $1
+		ORDER BY created_at DESC
+	`, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var saves []models.SavedGame
+	for rows.Next() {
+		var save models.SavedGame
+		if err := rows.Scan(&save.ID, &save.PlayerID, &save.RunType, &save.WaveLevel, &save.CreatedAt); err != nil {
+			return nil, err
+		}
+		saves = append(saves, save)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return saves, nil
+}
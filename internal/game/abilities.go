@@ -0,0 +1,189 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"realtime-game-backend/internal/models"
+)
+
+// DamageEnemy applies a hit to an enemy after resistances, armor and shields and returns the damage dealt.
+// Resistances scale the hit by damage type, armor reduces every hit but can't reduce it below 1,
+// and the shield absorbs damage before health.
+func DamageEnemy(enemy *models.Enemy, damage int, damageType string) int {
+	damage = int(float64(damage) * DamageMultiplier(*enemy, damageType))
+	damage -= enemy.Abilities.Armor
+	if damage < 1 {
+		damage = 1
+	}
+
+	// The shield absorbs what it can
+	absorbed := min(enemy.Shield, damage)
+	enemy.Shield -= absorbed
+
+	enemy.Health -= damage - absorbed
+	return damage
+}
+
+// SplitEnemy creates the children of a splitting enemy that has just died
+func SplitEnemy(parent models.Enemy) []models.Enemy {
+	if parent.Abilities.SplitCount <= 0 {
+		return nil
+	}
+
+	return spawnChildren(parent, parent.Abilities.SplitType, parent.Abilities.SplitCount, parent.ID+"-split")
+}
+
+// spawnChildren creates enemies of a type at a parent's position, inheriting its level scaling
+func spawnChildren(parent models.Enemy, childType string, count int, idPrefix string) []models.Enemy {
+	base, ok := models.GetEnemyTypes()[childType]
+	if !ok {
+		return nil
+	}
+
+	scale := levelScale(parent)
+	health := int(float64(base.Health) * scale)
+	shield := int(float64(base.Abilities.Shield) * scale)
+
+	children := make([]models.Enemy, count)
+	for i := range children {
+		children[i] = models.Enemy{
+			ID:          fmt.Sprintf("%s-%d", idPrefix, i),
+			Type:        base.Type,
+			Health:      health,
+			MaxHealth:   health,
+			Speed:       base.Speed,
+			Damage:      base.Damage,
+			Gold:        base.Gold,
+			X:           parent.X,
+			Y:           parent.Y,
+			PathIndex:   parent.PathIndex,
+			Lane:        parent.Lane,
+			Path:        parent.Path,
+			Active:      true,
+			SpawnAt:     parent.SpawnAt,
+			Spawned:     true,
+			Abilities:   base.Abilities,
+			Shield:      shield,
+			MaxShield:   shield,
+			Resistances: base.Resistances,
+		}
+	}
+
+	return children
+}
+
+// SlowEnemy slows an enemy to a fraction of its speed until a time in milliseconds.
+// Slows don't stack: the strongest one applies, and hits as strong as it keep it going.
+func SlowEnemy(enemy *models.Enemy, factor float64, until int64) {
+	switch {
+	case enemy.Slow == 0 || factor < enemy.Slow:
+		enemy.Slow = factor
+		enemy.SlowedUntil = until
+	case factor == enemy.Slow:
+		enemy.SlowedUntil = max(enemy.SlowedUntil, until)
+	}
+}
+
+// ExpireSlows lifts the slows that have worn off at a time in milliseconds
+func ExpireSlows(wave models.EnemyWave, now int64) models.EnemyWave {
+	for i, enemy := range wave.Enemies {
+		if enemy.Slow > 0 && now >= enemy.SlowedUntil {
+			wave.Enemies[i].Slow = 0
+			wave.Enemies[i].SlowedUntil = 0
+		}
+	}
+	return wave
+}
+
+// levelScale returns how much an enemy's health was scaled from its base stats
+func levelScale(enemy models.Enemy) float64 {
+	baseHealth := 0
+	if enemy.BossID != "" {
+		if def, ok := bossSet.Get(enemy.BossID); ok {
+			baseHealth = def.Health
+		}
+	} else if base, ok := models.GetEnemyTypes()[enemy.Type]; ok {
+		baseHealth = base.Health
+	}
+
+	if baseHealth <= 0 {
+		return 1
+	}
+	return float64(enemy.MaxHealth) / float64(baseHealth)
+}
+
+// combineAbilities adds extra abilities on top of an enemy type's own
+func combineAbilities(base, extra models.EnemyAbilities) models.EnemyAbilities {
+	base.Armor += extra.Armor
+	base.Shield += extra.Shield
+	base.ShieldRegen += extra.ShieldRegen
+	base.Regen += extra.Regen
+	base.Flying = base.Flying || extra.Flying
+	base.Invisible = base.Invisible || extra.Invisible
+	if extra.SplitCount > 0 {
+		base.SplitCount = extra.SplitCount
+		base.SplitType = extra.SplitType
+	}
+	return base
+}
+
+// RegenerateEnemies restores health and shields of regenerating enemies
+func RegenerateEnemies(wave models.EnemyWave, delta time.Duration) models.EnemyWave {
+	seconds := delta.Seconds()
+
+	for i, enemy := range wave.Enemies {
+		if !enemy.Active {
+			continue
+		}
+
+		if enemy.Abilities.Regen > 0 && enemy.Health < enemy.MaxHealth {
+			progress := enemy.RegenProgress + enemy.Abilities.Regen*seconds
+			healed := int(progress)
+			wave.Enemies[i].RegenProgress = progress - float64(healed)
+			wave.Enemies[i].Health = min(enemy.Health+healed, enemy.MaxHealth)
+		}
+
+		if enemy.Abilities.ShieldRegen > 0 && enemy.Shield < enemy.MaxShield {
+			progress := enemy.ShieldProgress + enemy.Abilities.ShieldRegen*seconds
+			restored := int(progress)
+			wave.Enemies[i].ShieldProgress = progress - float64(restored)
+			wave.Enemies[i].Shield = min(enemy.Shield+restored, enemy.MaxShield)
+		}
+	}
+
+	return wave
+}
+
+// shortcutPath builds the path flying enemies take by skipping every other waypoint
+func shortcutPath(path []models.Point) []models.Point {
+	if len(path) <= 2 {
+		return path
+	}
+
+	var shortcut []models.Point
+	for i := 0; i < len(path)-1; i += 2 {
+		shortcut = append(shortcut, path[i])
+	}
+	return append(shortcut, path[len(path)-1])
+}
+
+// PathFor returns the path an enemy follows in a wave
+func PathFor(wave models.EnemyWave, enemy models.Enemy) []models.Point {
+	if len(enemy.Path) > 0 {
+		return enemy.Path
+	}
+
+	if enemy.Lane > 0 && enemy.Lane < len(wave.Lanes) {
+		lane := wave.Lanes[enemy.Lane]
+		if enemy.Abilities.Flying && len(lane.FlyingPath) > 1 {
+			return lane.FlyingPath
+		}
+		return lane.Path
+	}
+
+	if enemy.Abilities.Flying && len(wave.FlyingPath) > 1 {
+		return wave.FlyingPath
+	}
+	return wave.Path
+}
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"testing"
+
+	"realtime-game-backend/internal/models"
+)
+
+func TestSlowEnemy(t *testing.T) {
+	tests := []struct {
+		name  string
+		hits  []float64 // Slow factors, one hit per second
+		slow  float64
+		until int64
+	}{
+		{"single hit", []float64{0.7}, 0.7, 2000},
+		{"repeated hits don't stack", []float64{0.7, 0.7, 0.7}, 0.7, 4000},
+		{"stronger slow replaces", []float64{0.7, 0.5}, 0.5, 3000},
+		{"weaker slow is ignored", []float64{0.5, 0.7}, 0.5, 2000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enemy := models.Enemy{Speed: 2}
+			for i, factor := range tt.hits {
+				SlowEnemy(&enemy, factor, int64(i)*1000+2000)
+			}
+
+			if enemy.Slow != tt.slow || enemy.SlowedUntil != tt.until {
+				t.Fatalf("got slow %v until %d, want %v until %d", enemy.Slow, enemy.SlowedUntil, tt.slow, tt.until)
+			}
+			if enemy.Speed != 2 {
+				t.Fatalf("base speed changed to %v", enemy.Speed)
+			}
+		})
+	}
+}
+
+func TestSlowWearsOff(t *testing.T) {
+	path := []models.Point{{X: 0, Y: 0}, {X: 1000, Y: 0}}
+	wave := models.EnemyWave{
+		Path:    path,
+		Lanes:   []models.Lane{{Path: path}},
+		Enemies: []models.Enemy{{Speed: 1, Active: true, Spawned: true}},
+	}
+	SlowEnemy(&wave.Enemies[0], 0.5, 1000)
+
+	// Slowed enemies cover half the distance
+	wave = UpdateEnemyPositions(wave, 10)
+	if x := wave.Enemies[0].X; x != 5 {
+		t.Fatalf("slowed enemy moved to %v, want 5", x)
+	}
+
+	wave = ExpireSlows(wave, 999)
+	if wave.Enemies[0].Slow == 0 {
+		t.Fatal("slow wore off early")
+	}
+
+	wave = ExpireSlows(wave, 1000)
+	wave = UpdateEnemyPositions(wave, 10)
+	if x := wave.Enemies[0].X; x != 15 {
+		t.Fatalf("enemy moved to %v after the slow wore off, want 15", x)
+	}
+}
+
+func TestSpawnChildrenGetTheirShields(t *testing.T) {
+	shielded := models.GetEnemyTypes()["shielded"]
+	parent := models.Enemy{
+		ID:        "parent",
+		Type:      "basic",
+		MaxHealth: models.GetEnemyTypes()["basic"].Health * 2, // Scaled to twice the base health
+	}
+
+	children := spawnChildren(parent, "shielded", 2, "child")
+	if len(children) != 2 {
+		t.Fatalf("got %d children, want 2", len(children))
+	}
+	for _, child := range children {
+		want := shielded.Abilities.Shield * 2
+		if child.Shield != want || child.MaxShield != want {
+			t.Fatalf("child has shield %d/%d, want %d", child.Shield, child.MaxShield, want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package game
+
+import (
+	"realtime-game-backend/internal/models"
+)
+
+// ResolveTowerStats returns a copy of the towers with support aura bonuses applied.
+// Bonuses from overlapping auras stack additively, and only damage towers are buffed.
+func ResolveTowerStats(towers []models.Tower) []models.Tower {
+	resolved := make([]models.Tower, len(towers))
+	copy(resolved, towers)
+
+	for i, tower := range towers {
+		if tower.Category != DamageCategory {
+			continue
+		}
+
+		// Sum the bonuses of every aura covering this tower
+		var rangeBonus, damageBonus, speedBonus float64
+		detection := tower.Detection
+		for j, support := range towers {
+			if i == j || support.Category != SupportCategory || support.AuraRadius <= 0 {
+				continue
+			}
+
+			point := models.Point{X: tower.X, Y: tower.Y}
+			center := models.Point{X: support.X, Y: support.Y}
+			if distance(point, center) > support.AuraRadius {
+				continue
+			}
+
+			rangeBonus += support.AuraRangeBonus
+			damageBonus += support.AuraDamageBonus
+			speedBonus += support.AuraSpeedBonus
+			detection = detection || support.AuraDetection
+		}
+
+		resolved[i].Range = tower.Range * (1 + rangeBonus)
+		resolved[i].Damage = int(float64(tower.Damage) * (1 + damageBonus))
+		resolved[i].Speed = tower.Speed * (1 + speedBonus)
+		resolved[i].Detection = detection
+	}
+
+	return resolved
+}
+
+// CalculateBankGold calculates the gold generated by economy towers for each player
+func CalculateBankGold(towers []models.Tower) map[string]int {
+	gold := make(map[string]int)
+	for _, tower := range towers {
+		if tower.Category == EconomyCategory && tower.GoldPerWave > 0 {
+			gold[tower.PlayerID] += tower.GoldPerWave
+		}
+	}
+	return gold
+}
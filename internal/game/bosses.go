@@ -0,0 +1,277 @@
+package game
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"realtime-game-backend/internal/models"
+)
+
+// Error definitions
+var (
+	ErrUnknownBoss = errors.New("unknown boss")
+)
+
+// defaultBossSet is the boss set shipped with the server
+//
+//go:embed data/bosses.json
+var defaultBossSet []byte
+
+// bossSet holds the definitions of every boss, set along with the wave set by SetWaveData
+var bossSet *BossSet
+
+// BossMinions describes a group of minions spawned by a boss phase
+type BossMinions struct {
+	EnemyType string `json:"enemyType"`
+	Count     int    `json:"count"`
+}
+
+// BossPhase describes what happens when a boss falls below a health threshold
+type BossPhase struct {
+	Name            string        `json:"name"`
+	HealthThreshold float64       `json:"healthThreshold"`           // Fraction of max health at which the phase starts, e.g. 0.5
+	SpawnMinions    []BossMinions `json:"spawnMinions,omitempty"`    // Minions spawned at the boss's position
+	ImmuneTo        []string      `json:"immuneTo,omitempty"`        // Tower types that can't damage the boss from this phase on
+	SpeedMultiplier float64       `json:"speedMultiplier,omitempty"` // Applied to the boss's speed
+	DisableRadius   float64       `json:"disableRadius,omitempty"`   // Towers within this radius stop firing
+	DisableDuration int64         `json:"disableDuration,omitempty"` // Milliseconds towers stay disabled
+}
+
+// BossRewards describes the rewards for killing a boss
+type BossRewards struct {
+	Gold  int `json:"gold"`
+	Score int `json:"score"`
+}
+
+// BossDefinition describes a scripted boss
+type BossDefinition struct {
+	ID          string                `json:"id"`
+	Name        string                `json:"name"`
+	Health      int                   `json:"health"`
+	Speed       float64               `json:"speed"`
+	Damage      int                   `json:"damage"`
+	Abilities   models.EnemyAbilities `json:"abilities"`
+	Resistances map[string]float64    `json:"resistances,omitempty"` // Damage multipliers by damage type
+	Phases      []BossPhase           `json:"phases"`
+	Rewards     BossRewards           `json:"rewards"`
+}
+
+// BossSet holds the definitions of every boss
+type BossSet struct {
+	Bosses []BossDefinition `json:"bosses"`
+
+	byID map[string]BossDefinition
+}
+
+// BossPhaseChange describes a boss entering a new phase during a simulation
+type BossPhaseChange struct {
+	EnemyID          string         `json:"enemyId"`
+	BossID           string         `json:"bossId"`
+	Phase            int            `json:"phase"` // 1-based index of the phase entered
+	Name             string         `json:"name"`
+	Elapsed          int64          `json:"elapsed"` // Milliseconds after the wave started
+	Speed            float64        `json:"speed"`
+	ImmuneTo         []string       `json:"immuneTo,omitempty"`
+	Minions          []models.Enemy `json:"minions,omitempty"`
+	DisabledTowerIDs []string       `json:"disabledTowerIds,omitempty"`
+	DisabledUntil    int64          `json:"disabledUntil,omitempty"`
+}
+
+// ParseBossSet parses and validates a JSON boss set
+func ParseBossSet(data []byte) (*BossSet, error) {
+	var set BossSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	if err := set.Validate(); err != nil {
+		return nil, err
+	}
+
+	set.byID = make(map[string]BossDefinition, len(set.Bosses))
+	for _, def := range set.Bosses {
+		// Phases trigger from the highest threshold down
+		sort.SliceStable(def.Phases, func(i, j int) bool {
+			return def.Phases[i].HealthThreshold > def.Phases[j].HealthThreshold
+		})
+		set.byID[def.ID] = def
+	}
+
+	return &set, nil
+}
+
+// LoadBossSet loads and validates a boss set from a JSON file
+func LoadBossSet(path string) (*BossSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := ParseBossSet(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return set, nil
+}
+
+// Validate checks that every boss definition is complete and only uses known enemy types
+func (s *BossSet) Validate() error {
+	enemyTypes := models.GetEnemyTypes()
+	seen := make(map[string]bool)
+
+	for _, def := range s.Bosses {
+		if def.ID == "" {
+			return errors.New("boss definition is missing an id")
+		}
+		if seen[def.ID] {
+			return fmt.Errorf("duplicate boss %q", def.ID)
+		}
+		seen[def.ID] = true
+
+		if def.Health <= 0 || def.Speed <= 0 {
+			return fmt.Errorf("boss %q: needs a positive health and speed", def.ID)
+		}
+		for damageType, multiplier := range def.Resistances {
+			if !IsDamageType(damageType) || multiplier < 0 {
+				return fmt.Errorf("boss %q: invalid resistance %q", def.ID, damageType)
+			}
+		}
+		if def.Rewards.Gold < 0 || def.Rewards.Score < 0 {
+			return fmt.Errorf("boss %q: rewards can't be negative", def.ID)
+		}
+
+		for _, phase := range def.Phases {
+			if phase.HealthThreshold <= 0 || phase.HealthThreshold >= 1 {
+				return fmt.Errorf("boss %q: phase %q threshold must be between 0 and 1", def.ID, phase.Name)
+			}
+			if phase.SpeedMultiplier < 0 || phase.DisableRadius < 0 || phase.DisableDuration < 0 {
+				return fmt.Errorf("boss %q: phase %q can't have negative values", def.ID, phase.Name)
+			}
+			for _, minions := range phase.SpawnMinions {
+				if _, ok := enemyTypes[minions.EnemyType]; !ok {
+					return fmt.Errorf("boss %q: phase %q spawns unknown enemy type %q", def.ID, phase.Name, minions.EnemyType)
+				}
+				if minions.Count <= 0 {
+					return fmt.Errorf("boss %q: phase %q needs a positive minion count", def.ID, phase.Name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Get returns the definition of a boss
+func (s *BossSet) Get(bossID string) (BossDefinition, bool) {
+	def, ok := s.byID[bossID]
+	return def, ok
+}
+
+// GetBossSet returns the boss definitions used by every wave
+func GetBossSet() *BossSet {
+	return bossSet
+}
+
+// GetBossDefinition returns the definition of a boss
+func GetBossDefinition(bossID string) (BossDefinition, error) {
+	def, ok := bossSet.Get(bossID)
+	if !ok {
+		return BossDefinition{}, fmt.Errorf("%w: %q", ErrUnknownBoss, bossID)
+	}
+	return def, nil
+}
+
+// isImmune checks if an enemy is immune to a tower type
+func isImmune(enemy models.Enemy, towerType string) bool {
+	for _, immune := range enemy.ImmuneTo {
+		if immune == towerType {
+			return true
+		}
+	}
+	return false
+}
+
+// updateBossPhases moves every boss that crossed a health threshold into its next phase
+func (s *Simulation) updateBossPhases() {
+	for i := range s.Wave.Enemies {
+		enemy := s.Wave.Enemies[i]
+		if enemy.BossID == "" || !enemy.Active {
+			continue
+		}
+
+		def, ok := bossSet.Get(enemy.BossID)
+		if !ok {
+			continue
+		}
+
+		// A single big hit can cross several thresholds at once
+		for enemy.Phase < len(def.Phases) {
+			phase := def.Phases[enemy.Phase]
+			if float64(enemy.Health) > phase.HealthThreshold*float64(enemy.MaxHealth) {
+				break
+			}
+			enemy.Phase++
+			s.enterBossPhase(i, enemy.Phase, phase)
+			enemy = s.Wave.Enemies[i]
+		}
+	}
+}
+
+// enterBossPhase applies a boss phase to the boss at index i and records the change
+func (s *Simulation) enterBossPhase(i, number int, phase BossPhase) {
+	boss := &s.Wave.Enemies[i]
+	boss.Phase = number
+
+	change := BossPhaseChange{
+		EnemyID: boss.ID,
+		BossID:  boss.BossID,
+		Phase:   number,
+		Name:    phase.Name,
+		Elapsed: s.Elapsed,
+	}
+
+	if phase.SpeedMultiplier > 0 {
+		boss.Speed *= phase.SpeedMultiplier
+	}
+
+	if len(phase.ImmuneTo) > 0 {
+		boss.ImmuneTo = append(boss.ImmuneTo, phase.ImmuneTo...)
+	}
+
+	change.Speed = boss.Speed
+	change.ImmuneTo = boss.ImmuneTo
+
+	// Disable towers near the boss
+	if phase.DisableRadius > 0 && phase.DisableDuration > 0 {
+		center := models.Point{X: boss.X, Y: boss.Y}
+		change.DisabledUntil = s.Elapsed + phase.DisableDuration
+		for j, tower := range s.Towers {
+			if distance(center, models.Point{X: tower.X, Y: tower.Y}) <= phase.DisableRadius {
+				s.Towers[j].DisabledUntil = max(tower.DisabledUntil, change.DisabledUntil)
+				change.DisabledTowerIDs = append(change.DisabledTowerIDs, tower.ID)
+			}
+		}
+	}
+
+	// Spawn minions last, since appending may move the boss in memory
+	minions := spawnMinions(*boss, number, phase.SpawnMinions)
+	change.Minions = minions
+	s.Wave.Enemies = append(s.Wave.Enemies, minions...)
+
+	s.phaseChanges = append(s.phaseChanges, change)
+}
+
+// spawnMinions creates the minions a boss spawns when entering a phase
+func spawnMinions(boss models.Enemy, phase int, groups []BossMinions) []models.Enemy {
+	var minions []models.Enemy
+	for _, group := range groups {
+		prefix := fmt.Sprintf("%s-phase-%d-%s", boss.ID, phase, group.EnemyType)
+		minions = append(minions, spawnChildren(boss, group.EnemyType, group.Count, prefix)...)
+	}
+	return minions
+}
@@ -0,0 +1,152 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"realtime-game-backend/internal/models"
+)
+
+// bossSimulation starts a simulation of a wave holding a single active boss from the shipped boss set
+func bossSimulation(t *testing.T, bossID string, towers []models.Tower) (*Simulation, BossDefinition) {
+	t.Helper()
+
+	def, err := GetBossDefinition(bossID)
+	if err != nil {
+		t.Fatalf("getting boss: %v", err)
+	}
+
+	wave := CreateEnemyWave(WaveDefinition{Level: 1, Bosses: []WaveBoss{{BossID: bossID}}}, nil)
+	if len(wave.Enemies) != 1 {
+		t.Fatalf("got a wave of %d enemies, want the boss alone", len(wave.Enemies))
+	}
+	wave.Enemies[0].Active, wave.Enemies[0].Spawned = true, true
+
+	return NewSimulation(towers, wave), def
+}
+
+// damageBoss brings the boss down to a fraction of its max health
+func damageBoss(sim *Simulation, fraction float64) {
+	boss := &sim.Wave.Enemies[0]
+	boss.Health = int(fraction * float64(boss.MaxHealth))
+}
+
+func TestBossCrossesSeveralPhasesInOneHit(t *testing.T) {
+	sim, def := bossSimulation(t, "hive_queen", nil)
+	speed := sim.Wave.Enemies[0].Speed
+
+	// Below every threshold at once
+	damageBoss(sim, 0.1)
+	sim.updateBossPhases()
+
+	changes := sim.DrainBossPhaseChanges()
+	if len(changes) != len(def.Phases) {
+		t.Fatalf("got %d phase changes, want %d", len(changes), len(def.Phases))
+	}
+	minions := 0
+	for i, change := range changes {
+		if change.Phase != i+1 || change.Name != def.Phases[i].Name {
+			t.Errorf("change %d entered phase %d %q, want %d %q", i, change.Phase, change.Name, i+1, def.Phases[i].Name)
+		}
+		minions += len(change.Minions)
+	}
+
+	boss := sim.Wave.Enemies[0]
+	if boss.Phase != len(def.Phases) {
+		t.Fatalf("boss is in phase %d, want %d", boss.Phase, len(def.Phases))
+	}
+	if len(sim.Wave.Enemies) != 1+minions {
+		t.Fatalf("wave has %d enemies, want the boss and %d minions", len(sim.Wave.Enemies), minions)
+	}
+	if boss.Speed != speed*1.5 {
+		t.Fatalf("boss speed is %v, want %v after the frenzy", boss.Speed, speed*1.5)
+	}
+	if !isImmune(boss, SniperTower) {
+		t.Fatal("boss isn't immune to snipers after the carapace")
+	}
+
+	// Phases are only entered once
+	sim.updateBossPhases()
+	if changes := sim.DrainBossPhaseChanges(); len(changes) != 0 {
+		t.Fatalf("got %d more phase changes without more damage", len(changes))
+	}
+}
+
+func TestBossPhaseSpawnsMinions(t *testing.T) {
+	sim, def := bossSimulation(t, "warlord", nil)
+	rally := def.Phases[0]
+
+	// Only the first threshold is crossed
+	damageBoss(sim, (rally.HealthThreshold+def.Phases[1].HealthThreshold)/2)
+	sim.updateBossPhases()
+
+	changes := sim.DrainBossPhaseChanges()
+	if len(changes) != 1 || changes[0].Name != rally.Name {
+		t.Fatalf("got phase changes %+v, want only %s", changes, rally.Name)
+	}
+
+	boss := sim.Wave.Enemies[0]
+	minions := sim.Wave.Enemies[1:]
+	if len(minions) != rally.SpawnMinions[0].Count || len(changes[0].Minions) != len(minions) {
+		t.Fatalf("got %d minions, %d in the change, want %d", len(minions), len(changes[0].Minions), rally.SpawnMinions[0].Count)
+	}
+	for _, minion := range minions {
+		if minion.Type != rally.SpawnMinions[0].EnemyType {
+			t.Errorf("minion %s is a %s, want %s", minion.ID, minion.Type, rally.SpawnMinions[0].EnemyType)
+		}
+		if minion.X != boss.X || minion.Y != boss.Y || !minion.Active {
+			t.Errorf("minion %s is at (%v, %v) active %v, want active at the boss", minion.ID, minion.X, minion.Y, minion.Active)
+		}
+		if !strings.HasPrefix(minion.ID, boss.ID+"-phase-1-") {
+			t.Errorf("minion ID %q isn't derived from the boss", minion.ID)
+		}
+	}
+}
+
+func TestBossPhaseDisablesTowers(t *testing.T) {
+	def, err := GetBossDefinition("hive_queen")
+	if err != nil {
+		t.Fatalf("getting boss: %v", err)
+	}
+	carapace := def.Phases[1]
+
+	// One tower next to where the boss spawns and one beyond the disable radius, both able to hit it
+	start := CreateEnemyWave(WaveDefinition{Level: 1, Bosses: []WaveBoss{{BossID: def.ID}}}, nil).Enemies[0]
+	var towers []models.Tower
+	for i, offset := range []float64{0, carapace.DisableRadius + 50} {
+		tower, err := CreateTower("alice", BasicTower, start.X+offset, start.Y)
+		if err != nil {
+			t.Fatalf("creating tower: %v", err)
+		}
+		tower.ID = []string{"near", "far"}[i]
+		tower.Range, tower.Speed = 1000, 10
+		towers = append(towers, tower)
+	}
+
+	sim, _ := bossSimulation(t, def.ID, towers)
+	damageBoss(sim, carapace.HealthThreshold)
+	sim.updateBossPhases()
+
+	changes := sim.DrainBossPhaseChanges()
+	if len(changes) != 2 {
+		t.Fatalf("got %d phase changes, want 2", len(changes))
+	}
+	disabled := changes[1]
+	if len(disabled.DisabledTowerIDs) != 1 || disabled.DisabledTowerIDs[0] != "near" {
+		t.Fatalf("disabled towers %v, want only near", disabled.DisabledTowerIDs)
+	}
+	if disabled.DisabledUntil != sim.Elapsed+carapace.DisableDuration {
+		t.Fatalf("towers disabled until %d, want %d", disabled.DisabledUntil, sim.Elapsed+carapace.DisableDuration)
+	}
+
+	// The disabled tower holds fire while the other keeps shooting
+	for i := 0; i < 10; i++ {
+		sim.Tick(frameDuration)
+	}
+	if shots := sim.Stats["near"].ShotsFired; shots != 0 {
+		t.Fatalf("disabled tower fired %d shots", shots)
+	}
+	if sim.Stats["far"].ShotsFired == 0 {
+		t.Fatal("tower outside the radius stopped firing")
+	}
+}
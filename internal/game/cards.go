@@ -40,8 +40,11 @@ func NewDeck() []models.Card {
 
 // ShuffleDeck shuffles a deck of cards
 func ShuffleDeck(deck []models.Card) []models.Card {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return ShuffleDeckWith(deck, rand.New(rand.NewSource(time.Now().UnixNano())))
+}
 
+// ShuffleDeckWith shuffles a deck of cards with a given random source, so seeded games deal the same cards
+func ShuffleDeckWith(deck []models.Card, r *rand.Rand) []models.Card {
 	// Fisher-Yates shuffle algorithm
 	for i := len(deck) - 1; i > 0; i-- {
 		j := r.Intn(i + 1)
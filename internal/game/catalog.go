@@ -0,0 +1,212 @@
+package game
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Error definitions
+var (
+	ErrUnknownTowerType = errors.New("unknown tower type")
+	ErrMaxLevel         = errors.New("tower is already at its maximum level")
+)
+
+// defaultTowerCatalog is the tower catalog shipped with the server
+//
+//go:embed data/towers.json
+var defaultTowerCatalog []byte
+
+// towerCatalog is the catalog used by all tower code paths
+var towerCatalog *TowerCatalog
+
+func init() {
+	catalog, err := ParseTowerCatalog(defaultTowerCatalog)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default tower catalog: %v", err))
+	}
+	towerCatalog = catalog
+}
+
+// AuraDefinition describes the buff a support tower grants to nearby damage towers
+type AuraDefinition struct {
+	Radius      float64 `json:"radius"`
+	RangeBonus  float64 `json:"rangeBonus,omitempty"`  // Fractional bonus, e.g. 0.2 = +20%
+	DamageBonus float64 `json:"damageBonus,omitempty"` // Fractional bonus
+	SpeedBonus  float64 `json:"speedBonus,omitempty"`  // Fractional bonus
+	Detection   bool    `json:"detection,omitempty"`   // Lets towers in the aura target invisible enemies
+}
+
+// TowerDefinition describes a tower type in the catalog
+type TowerDefinition struct {
+	Type         string          `json:"type"`
+	Name         string          `json:"name"`
+	Category     string          `json:"category"` // "damage", "support", "economy"
+	Cost         int             `json:"cost"`
+	Range        float64         `json:"range,omitempty"`
+	Damage       int             `json:"damage,omitempty"`
+	DamageType   string          `json:"damageType,omitempty"`   // Defaults to physical
+	Speed        float64         `json:"speed,omitempty"`        // Attacks per second
+	Splash       bool            `json:"splash,omitempty"`       // Hits every enemy in range
+	SlowFactor   float64         `json:"slowFactor,omitempty"`   // Speed multiplier applied to enemies hit
+	SlowDuration int64           `json:"slowDuration,omitempty"` // Milliseconds an enemy stays slowed after a hit
+	GroundOnly   bool            `json:"groundOnly,omitempty"`   // Can't hit flying enemies
+	Detection    bool            `json:"detection,omitempty"`    // Can target invisible enemies
+	Aura         *AuraDefinition `json:"aura,omitempty"`
+	GoldPerWave  int             `json:"goldPerWave,omitempty"`
+	MaxLevel     int             `json:"maxLevel,omitempty"` // Overrides the catalog's maximum level
+}
+
+// UpgradeRules describe how towers scale with each level
+type UpgradeRules struct {
+	MaxLevel         int     `json:"maxLevel"`         // Highest level reached by upgrading or merging
+	StatMultiplier   float64 `json:"statMultiplier"`   // Range, attack speed and aura values
+	DamageMultiplier float64 `json:"damageMultiplier"` // Damage
+	CostMultiplier   float64 `json:"costMultiplier"`   // Tower value and the cost of each upgrade
+	IncomeMultiplier float64 `json:"incomeMultiplier"` // Gold per wave of economy towers
+}
+
+// TowerCatalog holds the definitions of every tower type
+type TowerCatalog struct {
+	Upgrades UpgradeRules      `json:"upgrades"`
+	Towers   []TowerDefinition `json:"towers"`
+
+	byType map[string]TowerDefinition
+}
+
+// ParseTowerCatalog parses and validates a JSON tower catalog
+func ParseTowerCatalog(data []byte) (*TowerCatalog, error) {
+	var catalog TowerCatalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, err
+	}
+
+	if err := catalog.Validate(); err != nil {
+		return nil, err
+	}
+
+	catalog.byType = make(map[string]TowerDefinition, len(catalog.Towers))
+	for i, def := range catalog.Towers {
+		if def.Category == DamageCategory && def.DamageType == "" {
+			def.DamageType = PhysicalDamage
+			catalog.Towers[i] = def
+		}
+		catalog.byType[def.Type] = def
+	}
+
+	return &catalog, nil
+}
+
+// LoadTowerCatalog loads and validates a tower catalog from a JSON file
+func LoadTowerCatalog(path string) (*TowerCatalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog, err := ParseTowerCatalog(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return catalog, nil
+}
+
+// Validate checks that every tower definition is complete and consistent
+func (c *TowerCatalog) Validate() error {
+	if len(c.Towers) == 0 {
+		return errors.New("tower catalog is empty")
+	}
+
+	u := c.Upgrades
+	if u.MaxLevel < 1 {
+		return errors.New("upgrades: maxLevel must be at least 1")
+	}
+	if u.StatMultiplier < 1 || u.DamageMultiplier < 1 || u.CostMultiplier < 1 || u.IncomeMultiplier < 1 {
+		return errors.New("upgrades: multipliers must be at least 1")
+	}
+
+	seen := make(map[string]bool)
+	for _, def := range c.Towers {
+		if def.Type == "" {
+			return errors.New("tower definition is missing a type")
+		}
+		if seen[def.Type] {
+			return fmt.Errorf("duplicate tower type %q", def.Type)
+		}
+		seen[def.Type] = true
+
+		if def.Cost <= 0 {
+			return fmt.Errorf("tower %q: cost must be positive", def.Type)
+		}
+		if def.MaxLevel < 0 {
+			return fmt.Errorf("tower %q: maxLevel can't be negative", def.Type)
+		}
+
+		switch def.Category {
+		case DamageCategory:
+			if def.Range <= 0 || def.Damage <= 0 || def.Speed <= 0 {
+				return fmt.Errorf("tower %q: damage towers need a positive range, damage and speed", def.Type)
+			}
+			if def.SlowFactor < 0 || def.SlowFactor >= 1 {
+				return fmt.Errorf("tower %q: slowFactor must be between 0 and 1", def.Type)
+			}
+			if (def.SlowFactor > 0) != (def.SlowDuration > 0) {
+				return fmt.Errorf("tower %q: slowFactor needs a positive slowDuration", def.Type)
+			}
+			if def.DamageType != "" && !IsDamageType(def.DamageType) {
+				return fmt.Errorf("tower %q: unknown damage type %q", def.Type, def.DamageType)
+			}
+		case SupportCategory:
+			if def.Aura == nil || def.Aura.Radius <= 0 {
+				return fmt.Errorf("tower %q: support towers need an aura with a positive radius", def.Type)
+			}
+			if def.Aura.RangeBonus <= 0 && def.Aura.DamageBonus <= 0 && def.Aura.SpeedBonus <= 0 && !def.Aura.Detection {
+				return fmt.Errorf("tower %q: aura must grant at least one bonus", def.Type)
+			}
+		case EconomyCategory:
+			if def.GoldPerWave <= 0 {
+				return fmt.Errorf("tower %q: economy towers need a positive goldPerWave", def.Type)
+			}
+		default:
+			return fmt.Errorf("tower %q: unknown category %q", def.Type, def.Category)
+		}
+	}
+
+	return nil
+}
+
+// Get returns the definition of a tower type
+func (c *TowerCatalog) Get(towerType string) (TowerDefinition, bool) {
+	def, ok := c.byType[towerType]
+	return def, ok
+}
+
+// MaxLevel returns the highest level a tower type can reach
+func (c *TowerCatalog) MaxLevel(towerType string) int {
+	if def, ok := c.byType[towerType]; ok && def.MaxLevel > 0 {
+		return def.MaxLevel
+	}
+	return c.Upgrades.MaxLevel
+}
+
+// SetTowerCatalog replaces the catalog used by all tower code paths
+func SetTowerCatalog(catalog *TowerCatalog) {
+	towerCatalog = catalog
+}
+
+// GetTowerCatalog returns the catalog used by all tower code paths
+func GetTowerCatalog() *TowerCatalog {
+	return towerCatalog
+}
+
+// GetTowerDefinition returns the catalog definition of a tower type
+func GetTowerDefinition(towerType string) (TowerDefinition, error) {
+	def, ok := towerCatalog.Get(towerType)
+	if !ok {
+		return TowerDefinition{}, fmt.Errorf("%w: %q", ErrUnknownTowerType, towerType)
+	}
+	return def, nil
+}
@@ -0,0 +1,104 @@
+package game
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// testCatalog returns a valid catalog with one tower of each category
+func testCatalog() TowerCatalog {
+	return TowerCatalog{
+		Upgrades: UpgradeRules{
+			MaxLevel:         5,
+			StatMultiplier:   1.2,
+			DamageMultiplier: 1.5,
+			CostMultiplier:   1.5,
+			IncomeMultiplier: 1.5,
+		},
+		Towers: []TowerDefinition{
+			{Type: "basic", Category: DamageCategory, Cost: 50, Range: 100, Damage: 10, Speed: 1},
+			{Type: "banner", Category: SupportCategory, Cost: 80, Aura: &AuraDefinition{Radius: 100, DamageBonus: 0.2}},
+			{Type: "bank", Category: EconomyCategory, Cost: 120, GoldPerWave: 20},
+		},
+	}
+}
+
+func TestTowerCatalogValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *TowerCatalog)
+		want   string // Part of the error
+	}{
+		{"empty", func(c *TowerCatalog) { c.Towers = nil }, "empty"},
+		{"missing type", func(c *TowerCatalog) { c.Towers[0].Type = "" }, "missing a type"},
+		{"duplicate type", func(c *TowerCatalog) { c.Towers[1].Type = "basic" }, "duplicate"},
+		{"zero max level", func(c *TowerCatalog) { c.Upgrades.MaxLevel = 0 }, "maxLevel"},
+		{"negative max level", func(c *TowerCatalog) { c.Upgrades.MaxLevel = -1 }, "maxLevel"},
+		{"negative tower max level", func(c *TowerCatalog) { c.Towers[0].MaxLevel = -1 }, "maxLevel"},
+		{"shrinking stats", func(c *TowerCatalog) { c.Upgrades.StatMultiplier = 0.9 }, "multipliers"},
+		{"shrinking damage", func(c *TowerCatalog) { c.Upgrades.DamageMultiplier = 0 }, "multipliers"},
+		{"shrinking cost", func(c *TowerCatalog) { c.Upgrades.CostMultiplier = 0.5 }, "multipliers"},
+		{"shrinking income", func(c *TowerCatalog) { c.Upgrades.IncomeMultiplier = -1 }, "multipliers"},
+		{"free tower", func(c *TowerCatalog) { c.Towers[0].Cost = 0 }, "cost"},
+		{"no damage", func(c *TowerCatalog) { c.Towers[0].Damage = 0 }, "damage towers"},
+		{"full slow", func(c *TowerCatalog) { c.Towers[0].SlowFactor, c.Towers[0].SlowDuration = 1, 1000 }, "slowFactor"},
+		{"endless slow", func(c *TowerCatalog) { c.Towers[0].SlowFactor = 0.5 }, "slowDuration"},
+		{"unknown damage type", func(c *TowerCatalog) { c.Towers[0].DamageType = "sonic" }, "damage type"},
+		{"no aura", func(c *TowerCatalog) { c.Towers[1].Aura = nil }, "aura"},
+		{"empty aura", func(c *TowerCatalog) { c.Towers[1].Aura.DamageBonus = 0 }, "bonus"},
+		{"no income", func(c *TowerCatalog) { c.Towers[2].GoldPerWave = 0 }, "goldPerWave"},
+		{"unknown category", func(c *TowerCatalog) { c.Towers[0].Category = "magic" }, "category"},
+	}
+
+	valid := testCatalog()
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("test catalog is invalid: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog := testCatalog()
+			aura := *catalog.Towers[1].Aura
+			catalog.Towers[1].Aura = &aura
+			tt.modify(&catalog)
+
+			err := catalog.Validate()
+			if err == nil {
+				t.Fatal("invalid catalog was accepted")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got error %q, want one about %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTowerCatalog(t *testing.T) {
+	data, err := json.Marshal(testCatalog())
+	if err != nil {
+		t.Fatalf("marshaling catalog: %v", err)
+	}
+
+	catalog, err := ParseTowerCatalog(data)
+	if err != nil {
+		t.Fatalf("parsing catalog: %v", err)
+	}
+
+	// Damage towers deal physical damage unless they say otherwise
+	basic, ok := catalog.Get("basic")
+	if !ok || basic.DamageType != PhysicalDamage {
+		t.Fatalf("got basic tower %+v, want physical damage", basic)
+	}
+	if _, ok := catalog.Get("banner"); !ok {
+		t.Fatal("support tower wasn't indexed")
+	}
+	if _, err := ParseTowerCatalog([]byte(`{"towers": []}`)); err == nil {
+		t.Fatal("parsed a catalog without towers")
+	}
+}
+
+func TestDefaultTowerCatalog(t *testing.T) {
+	if _, err := ParseTowerCatalog(defaultTowerCatalog); err != nil {
+		t.Fatalf("shipped catalog is invalid: %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package game
+
+import (
+	"realtime-game-backend/internal/models"
+)
+
+// Damage types
+const (
+	PhysicalDamage = "physical"
+	FireDamage     = "fire"
+	FrostDamage    = "frost"
+	PoisonDamage   = "poison"
+	ArcaneDamage   = "arcane"
+)
+
+// damageTypes holds every valid damage type
+var damageTypes = map[string]bool{
+	PhysicalDamage: true,
+	FireDamage:     true,
+	FrostDamage:    true,
+	PoisonDamage:   true,
+	ArcaneDamage:   true,
+}
+
+// IsDamageType checks if a damage type exists
+func IsDamageType(damageType string) bool {
+	return damageTypes[damageType]
+}
+
+// DamageMultiplier returns how much of a damage type an enemy takes.
+// Values below 1 are resistances and values above 1 are weaknesses.
+func DamageMultiplier(enemy models.Enemy, damageType string) float64 {
+	if multiplier, ok := enemy.Resistances[damageType]; ok {
+		return multiplier
+	}
+	return 1
+}
@@ -0,0 +1,66 @@
+package game
+
+import (
+	"testing"
+
+	"realtime-game-backend/internal/models"
+)
+
+func TestDamageEnemyResistances(t *testing.T) {
+	resistances := map[string]float64{
+		PhysicalDamage: 0.8,
+		FireDamage:     1.5,
+		FrostDamage:    0.5,
+		PoisonDamage:   0,
+	}
+
+	tests := []struct {
+		damageType string
+		dealt      int
+	}{
+		{PhysicalDamage, 80},
+		{FireDamage, 150},
+		{FrostDamage, 50},
+		{PoisonDamage, 1}, // Immune enemies still take a scratch
+		{ArcaneDamage, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.damageType, func(t *testing.T) {
+			if !IsDamageType(tt.damageType) {
+				t.Fatalf("%s isn't a damage type", tt.damageType)
+			}
+
+			enemy := models.Enemy{Health: 500, Resistances: resistances}
+			if dealt := DamageEnemy(&enemy, 100, tt.damageType); dealt != tt.dealt {
+				t.Fatalf("dealt %d, want %d", dealt, tt.dealt)
+			}
+			if enemy.Health != 500-tt.dealt {
+				t.Fatalf("enemy has %d health, want %d", enemy.Health, 500-tt.dealt)
+			}
+		})
+	}
+}
+
+func TestDamageEnemyAppliesResistanceBeforeArmorAndShield(t *testing.T) {
+	enemy := models.Enemy{
+		Health:      500,
+		Shield:      20,
+		Abilities:   models.EnemyAbilities{Armor: 10},
+		Resistances: map[string]float64{FireDamage: 0.5},
+	}
+
+	// 100 fire damage is halved, then armor takes 10 and the shield absorbs 20
+	if dealt := DamageEnemy(&enemy, 100, FireDamage); dealt != 40 {
+		t.Fatalf("dealt %d, want 40", dealt)
+	}
+	if enemy.Shield != 0 || enemy.Health != 480 {
+		t.Fatalf("enemy has %d shield and %d health, want 0 and 480", enemy.Shield, enemy.Health)
+	}
+}
+
+func TestIsDamageType(t *testing.T) {
+	if IsDamageType("sonic") || IsDamageType("") {
+		t.Fatal("accepted an unknown damage type")
+	}
+}
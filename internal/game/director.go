@@ -0,0 +1,267 @@
+package game
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"realtime-game-backend/internal/models"
+)
+
+// defaultDirectorConfig is the adaptive difficulty configuration shipped with the server
+//
+//go:embed data/director.json
+var defaultDirectorConfig []byte
+
+// directorConfig holds the bounds used by every adaptive director
+var directorConfig *DirectorConfig
+
+func init() {
+	config, err := ParseDirectorConfig(defaultDirectorConfig)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default director config: %v", err))
+	}
+	directorConfig = config
+}
+
+// DirectorConfig bounds how far the adaptive director can move a wave away from its definition
+type DirectorConfig struct {
+	Window              int     `json:"window"` // Number of recent waves and hands considered
+	MinHealthMultiplier float64 `json:"minHealthMultiplier"`
+	MaxHealthMultiplier float64 `json:"maxHealthMultiplier"`
+	MinCountMultiplier  float64 `json:"minCountMultiplier"`
+	MaxCountMultiplier  float64 `json:"maxCountMultiplier"`
+
+	// Under strong pressure either way, up to MaxSwaps groups change to a harder or easier enemy type
+	SwapPressure float64           `json:"swapPressure"`
+	MaxSwaps     int               `json:"maxSwaps"`
+	HarderTypes  map[string]string `json:"harderTypes"` // Enemy types thriving players face instead
+	EasierTypes  map[string]string `json:"easierTypes"` // Enemy types struggling players face instead
+
+	// Values at which a signal counts fully towards the pressure
+	LeakDamageCap float64 `json:"leakDamageCap"` // Base damage per wave
+	GoldBankedCap float64 `json:"goldBankedCap"` // Unspent gold when a wave starts
+	HandValueCap  float64 `json:"handValueCap"`  // Hand rank value, e.g. 5 for a straight
+}
+
+// ParseDirectorConfig parses and validates a JSON director configuration
+func ParseDirectorConfig(data []byte) (*DirectorConfig, error) {
+	var config DirectorConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// LoadDirectorConfig loads and validates a director configuration from a JSON file
+func LoadDirectorConfig(path string) (*DirectorConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := ParseDirectorConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// Validate checks that the bounds are consistent and keep waves unchanged in the middle
+func (c *DirectorConfig) Validate() error {
+	if c.Window <= 0 {
+		return errors.New("director window must be positive")
+	}
+	if c.MinHealthMultiplier <= 0 || c.MinHealthMultiplier > 1 || c.MaxHealthMultiplier < 1 {
+		return errors.New("director health multipliers must surround 1")
+	}
+	if c.MinCountMultiplier <= 0 || c.MinCountMultiplier > 1 || c.MaxCountMultiplier < 1 {
+		return errors.New("director count multipliers must surround 1")
+	}
+	if c.LeakDamageCap <= 0 || c.GoldBankedCap <= 0 || c.HandValueCap <= 1 {
+		return errors.New("director caps must be positive")
+	}
+	if c.SwapPressure <= 0 || c.SwapPressure > 1 || c.MaxSwaps < 0 {
+		return errors.New("director swapPressure must be between 0 and 1 and maxSwaps can't be negative")
+	}
+
+	enemyTypes := models.GetEnemyTypes()
+	for _, swaps := range []map[string]string{c.HarderTypes, c.EasierTypes} {
+		for from, to := range swaps {
+			if _, ok := enemyTypes[from]; !ok {
+				return fmt.Errorf("director swaps unknown enemy type %q", from)
+			}
+			if _, ok := enemyTypes[to]; !ok || to == from {
+				return fmt.Errorf("director can't swap %q for %q", from, to)
+			}
+		}
+	}
+	return nil
+}
+
+// SetDirectorConfig replaces the bounds used by adaptive directors created afterwards
+func SetDirectorConfig(config *DirectorConfig) {
+	directorConfig = config
+}
+
+// GetDirectorConfig returns the bounds used by adaptive directors
+func GetDirectorConfig() *DirectorConfig {
+	return directorConfig
+}
+
+// directorHistory holds a player's recent waves and hands
+type directorHistory struct {
+	leakDamage []int
+	handValues []int
+}
+
+// Director adapts upcoming waves to how each player is doing
+type Director struct {
+	Config  DirectorConfig
+	players map[string]*directorHistory
+}
+
+// NewDirector creates an adaptive director with the current configuration
+func NewDirector() *Director {
+	return &Director{
+		Config:  *directorConfig,
+		players: make(map[string]*directorHistory),
+	}
+}
+
+// history returns a player's history, creating it if needed
+func (d *Director) history(playerID string) *directorHistory {
+	history, ok := d.players[playerID]
+	if !ok {
+		history = &directorHistory{}
+		d.players[playerID] = history
+	}
+	return history
+}
+
+// RecordWave records the base damage a player took during a wave
+func (d *Director) RecordWave(playerID string, leakDamage int) {
+	history := d.history(playerID)
+	history.leakDamage = lastN(append(history.leakDamage, leakDamage), d.Config.Window)
+}
+
+// RecordHand records the rank value of a player's final poker hand
+func (d *Director) RecordHand(playerID string, handValue int) {
+	history := d.history(playerID)
+	history.handValues = lastN(append(history.handValues, handValue), d.Config.Window)
+}
+
+// Adjust adapts a wave definition for the player starting it.
+// Players who leak and lose health get weaker, smaller waves; players with banked gold and good hands get harder ones.
+// Under strong pressure the first groups with a configured swap also change enemy type.
+// Nothing changes until the player has fought a wave.
+func (d *Director) Adjust(def WaveDefinition, playerID string, goldBanked, baseHealth, maxHealth int) (WaveDefinition, models.DifficultyAdjustment, bool) {
+	history := d.history(playerID)
+	if len(history.leakDamage) == 0 {
+		return def, models.DifficultyAdjustment{}, false
+	}
+
+	adjustment := models.DifficultyAdjustment{
+		PlayerID:      playerID,
+		Level:         def.Level,
+		AvgLeakDamage: average(history.leakDamage),
+		AvgHandValue:  average(history.handValues),
+		GoldBanked:    goldBanked,
+		BaseHealth:    baseHealth,
+	}
+
+	// Struggling and thriving are each the mean of two signals between 0 and 1
+	struggling := (clamp01(adjustment.AvgLeakDamage/d.Config.LeakDamageCap) + clamp01(1-float64(baseHealth)/float64(maxHealth))) / 2
+	thriving := clamp01(float64(goldBanked) / d.Config.GoldBankedCap)
+	if len(history.handValues) > 0 {
+		thriving = (thriving + clamp01((adjustment.AvgHandValue-1)/(d.Config.HandValueCap-1))) / 2
+	}
+	adjustment.Pressure = thriving - struggling
+
+	adjustment.HealthMultiplier = scaleWithin(adjustment.Pressure, d.Config.MinHealthMultiplier, d.Config.MaxHealthMultiplier)
+	adjustment.CountMultiplier = scaleWithin(adjustment.Pressure, d.Config.MinCountMultiplier, d.Config.MaxCountMultiplier)
+	adjustment.Assisted = adjustment.Pressure < 0
+
+	// Copy the groups and bosses so cached definitions stay untouched
+	adjusted := def
+	adjusted.Groups = make([]WaveGroup, len(def.Groups))
+	for i, group := range def.Groups {
+		group.Count = max(1, int(math.Round(float64(group.Count)*adjustment.CountMultiplier)))
+		group.HealthMultiplier = orOne(group.HealthMultiplier) * adjustment.HealthMultiplier
+		adjusted.Groups[i] = group
+	}
+	adjustment.Swaps = d.swapGroups(adjusted.Groups, adjustment.Pressure)
+	adjusted.Bosses = make([]WaveBoss, len(def.Bosses))
+	for i, boss := range def.Bosses {
+		boss.HealthMultiplier = orOne(boss.HealthMultiplier) * adjustment.HealthMultiplier
+		adjusted.Bosses[i] = boss
+	}
+
+	return adjusted, adjustment, true
+}
+
+// swapGroups changes the enemy type of at most MaxSwaps groups when the pressure is strong enough
+func (d *Director) swapGroups(groups []WaveGroup, pressure float64) []models.EnemySwap {
+	if math.Abs(pressure) < d.Config.SwapPressure {
+		return nil
+	}
+
+	swaps := d.Config.HarderTypes
+	if pressure < 0 {
+		swaps = d.Config.EasierTypes
+	}
+
+	var swapped []models.EnemySwap
+	for i := range groups {
+		if len(swapped) >= d.Config.MaxSwaps {
+			break
+		}
+		if to, ok := swaps[groups[i].EnemyType]; ok {
+			swapped = append(swapped, models.EnemySwap{Group: i, From: groups[i].EnemyType, To: to})
+			groups[i].EnemyType = to
+		}
+	}
+	return swapped
+}
+
+// scaleWithin maps a pressure between -1 and 1 onto a multiplier, with 0 mapping to 1
+func scaleWithin(pressure, low, high float64) float64 {
+	if pressure < 0 {
+		return 1 + pressure*(1-low)
+	}
+	return 1 + pressure*(high-1)
+}
+
+// clamp01 limits a value to the range 0 to 1
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// average returns the mean of a list of values, or 0 for an empty list
+func average(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return float64(total) / float64(len(values))
+}
+
+// lastN returns the last n values of a list
+func lastN(values []int, n int) []int {
+	if len(values) > n {
+		return values[len(values)-n:]
+	}
+	return values
+}
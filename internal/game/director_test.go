@@ -0,0 +1,189 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// testDirector returns a director with fixed bounds so the expected multipliers don't follow director.json
+func testDirector() *Director {
+	d := NewDirector()
+	d.Config = DirectorConfig{
+		Window:              3,
+		MinHealthMultiplier: 0.7,
+		MaxHealthMultiplier: 1.3,
+		MinCountMultiplier:  0.75,
+		MaxCountMultiplier:  1.25,
+		LeakDamageCap:       5,
+		GoldBankedCap:       300,
+		HandValueCap:        5,
+		SwapPressure:        0.5,
+		MaxSwaps:            1,
+		HarderTypes:         map[string]string{"basic": "armored"},
+		EasierTypes:         map[string]string{"tank": "basic"},
+	}
+	return d
+}
+
+// testDirectorWave returns a wave definition with two swappable groups and a boss
+func testDirectorWave() WaveDefinition {
+	return WaveDefinition{
+		Level: 2,
+		Groups: []WaveGroup{
+			{EnemyType: "basic", Count: 8},
+			{EnemyType: "tank", Count: 4},
+			{EnemyType: "basic", Count: 4},
+			{EnemyType: "tank", Count: 2},
+		},
+		Bosses: []WaveBoss{{EnemyType: "boss"}},
+	}
+}
+
+// approx checks that two multipliers are equal up to rounding
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestDirectorWaitsForAWave(t *testing.T) {
+	d := testDirector()
+	def := testDirectorWave()
+
+	if _, _, ok := d.Adjust(def, "alice", 300, 20, 20); ok {
+		t.Fatal("adjusted a wave before the player fought one")
+	}
+}
+
+func TestDirectorAdjust(t *testing.T) {
+	tests := []struct {
+		name       string
+		leakDamage int
+		handValue  int // 0 for no hand played
+		gold       int
+		baseHealth int
+		pressure   float64
+		health     float64
+		count      float64
+		assisted   bool
+		groups     []string // Enemy types of the groups after swapping
+	}{
+		{
+			name:       "struggling",
+			leakDamage: 5, gold: 0, baseHealth: 10,
+			pressure: -0.75, health: 0.775, count: 0.8125, assisted: true,
+			groups: []string{"basic", "basic", "basic", "tank"},
+		},
+		{
+			name:       "thriving",
+			leakDamage: 0, handValue: 5, gold: 300, baseHealth: 20,
+			pressure: 1, health: 1.3, count: 1.25,
+			groups: []string{"armored", "tank", "basic", "tank"},
+		},
+		{
+			name:       "slightly ahead",
+			leakDamage: 0, gold: 60, baseHealth: 20,
+			pressure: 0.2, health: 1.06, count: 1.05,
+			groups: []string{"basic", "tank", "basic", "tank"},
+		},
+		{
+			name:       "slightly behind",
+			leakDamage: 1, gold: 0, baseHealth: 18,
+			pressure: -0.15, health: 0.955, count: 0.9625, assisted: true,
+			groups: []string{"basic", "tank", "basic", "tank"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := testDirector()
+			d.RecordWave("alice", tt.leakDamage)
+			if tt.handValue > 0 {
+				d.RecordHand("alice", tt.handValue)
+			}
+
+			def := testDirectorWave()
+			adjusted, change, ok := d.Adjust(def, "alice", tt.gold, tt.baseHealth, 20)
+			if !ok {
+				t.Fatal("wave wasn't adjusted")
+			}
+
+			if !approx(change.Pressure, tt.pressure) {
+				t.Errorf("got pressure %v, want %v", change.Pressure, tt.pressure)
+			}
+			if !approx(change.HealthMultiplier, tt.health) || !approx(change.CountMultiplier, tt.count) {
+				t.Errorf("got health %v and count %v, want %v and %v", change.HealthMultiplier, change.CountMultiplier, tt.health, tt.count)
+			}
+			if change.Assisted != tt.assisted {
+				t.Errorf("got assisted %v, want %v", change.Assisted, tt.assisted)
+			}
+
+			for i, group := range adjusted.Groups {
+				if group.EnemyType != tt.groups[i] {
+					t.Errorf("group %d is %s, want %s", i, group.EnemyType, tt.groups[i])
+				}
+				if want := max(1, int(math.Round(float64(def.Groups[i].Count)*tt.count))); group.Count != want {
+					t.Errorf("group %d has %d enemies, want %d", i, group.Count, want)
+				}
+				if !approx(group.HealthMultiplier, tt.health) {
+					t.Errorf("group %d has health multiplier %v, want %v", i, group.HealthMultiplier, tt.health)
+				}
+			}
+			if !approx(adjusted.Bosses[0].HealthMultiplier, tt.health) {
+				t.Errorf("boss has health multiplier %v, want %v", adjusted.Bosses[0].HealthMultiplier, tt.health)
+			}
+			if len(change.Swaps) > d.Config.MaxSwaps {
+				t.Errorf("swapped %d groups, at most %d allowed", len(change.Swaps), d.Config.MaxSwaps)
+			}
+
+			// The definition passed in is left alone
+			if def.Groups[0].EnemyType != "basic" || def.Groups[0].Count != 8 || def.Groups[0].HealthMultiplier != 0 {
+				t.Errorf("original definition changed: %+v", def.Groups[0])
+			}
+		})
+	}
+}
+
+func TestDirectorUsesRecentHistory(t *testing.T) {
+	d := testDirector()
+
+	// Old leaks fall out of the window
+	for _, damage := range []int{5, 5, 0, 0, 0} {
+		d.RecordWave("alice", damage)
+	}
+	_, change, _ := d.Adjust(testDirectorWave(), "alice", 0, 20, 20)
+	if change.AvgLeakDamage != 0 || change.Assisted {
+		t.Fatalf("got average leak damage %v and assisted %v, want 0 and false", change.AvgLeakDamage, change.Assisted)
+	}
+
+	// Each player has their own history
+	d.RecordWave("bob", 5)
+	if _, change, _ := d.Adjust(testDirectorWave(), "bob", 0, 20, 20); !change.Assisted {
+		t.Fatal("bob's leaks didn't make the wave easier")
+	}
+}
+
+func TestDirectorConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *DirectorConfig)
+	}{
+		{"zero window", func(c *DirectorConfig) { c.Window = 0 }},
+		{"health above 1", func(c *DirectorConfig) { c.MinHealthMultiplier = 1.1 }},
+		{"count below 1", func(c *DirectorConfig) { c.MaxCountMultiplier = 0.9 }},
+		{"no swap pressure", func(c *DirectorConfig) { c.SwapPressure = 0 }},
+		{"unknown swap", func(c *DirectorConfig) { c.HarderTypes = map[string]string{"basic": "dragon"} }},
+		{"swap to itself", func(c *DirectorConfig) { c.EasierTypes = map[string]string{"tank": "tank"} }},
+	}
+
+	if err := testDirector().Config.Validate(); err != nil {
+		t.Fatalf("test config is invalid: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testDirector().Config
+			tt.modify(&config)
+			if err := config.Validate(); err == nil {
+				t.Fatal("invalid config was accepted")
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"realtime-game-backend/internal/models"
+)
+
+// Run types
+const (
+	CampaignRun = "campaign" // Waves come from the wave set
+	EndlessRun  = "endless"  // Waves are generated from a seeded, growing point budget
+)
+
+// Endless wave budget
+const (
+	endlessBaseBudget   = 60.0 // Points available for the first wave
+	endlessBudgetGrowth = 1.15 // Budget multiplier per wave
+	endlessMaxGroups    = 6    // Budget beyond this many full groups buys extra health instead
+	endlessMinGroupSize = 3
+	endlessMaxGroupSize = 12
+	endlessGroupSpacing = 2000 // Milliseconds between groups
+	endlessBossEvery    = 10   // A boss joins every tenth wave
+)
+
+// endlessEnemy describes what an enemy type costs in an endless wave and when it becomes available
+type endlessEnemy struct {
+	Cost     float64
+	MinLevel int
+	Interval int64 // Milliseconds between spawns in a group
+}
+
+// Enemy types available to endless waves
+var endlessEnemies = map[string]endlessEnemy{
+	"basic":       {Cost: 4, MinLevel: 1, Interval: 1000},
+	"fast":        {Cost: 5, MinLevel: 2, Interval: 700},
+	"tank":        {Cost: 8, MinLevel: 3, Interval: 1400},
+	"swarmling":   {Cost: 2, MinLevel: 4, Interval: 300},
+	"armored":     {Cost: 10, MinLevel: 5, Interval: 1400},
+	"shielded":    {Cost: 10, MinLevel: 6, Interval: 1200},
+	"regenerator": {Cost: 9, MinLevel: 7, Interval: 1200},
+	"flyer":       {Cost: 9, MinLevel: 8, Interval: 900},
+	"splitter":    {Cost: 10, MinLevel: 9, Interval: 1300},
+	"ghost":       {Cost: 10, MinLevel: 10, Interval: 1000},
+}
+
+// endlessUpgrade is an ability or modifier a group can buy on top of its enemy type
+type endlessUpgrade struct {
+	CostMultiplier float64
+	MinLevel       int
+	Apply          func(group *WaveGroup)
+}
+
+// Abilities and modifiers available to endless groups
+var endlessUpgrades = []endlessUpgrade{
+	{CostMultiplier: 1.4, MinLevel: 5, Apply: func(g *WaveGroup) { extraAbilities(g).Armor += 3 }},
+	{CostMultiplier: 1.5, MinLevel: 8, Apply: func(g *WaveGroup) { extraAbilities(g).Shield += 60 }},
+	{CostMultiplier: 1.3, MinLevel: 8, Apply: func(g *WaveGroup) { extraAbilities(g).Regen += 8 }},
+	{CostMultiplier: 1.6, MinLevel: 12, Apply: func(g *WaveGroup) { extraAbilities(g).Invisible = true }},
+	{CostMultiplier: 1.5, MinLevel: 3, Apply: func(g *WaveGroup) { g.HealthMultiplier = orOne(g.HealthMultiplier) * 1.5 }},
+	{CostMultiplier: 1.3, MinLevel: 3, Apply: func(g *WaveGroup) { g.SpeedMultiplier = orOne(g.SpeedMultiplier) * 1.25 }},
+}
+
+// extraAbilities returns the group's extra abilities, creating them if needed
+func extraAbilities(group *WaveGroup) *models.EnemyAbilities {
+	if group.Abilities == nil {
+		group.Abilities = &models.EnemyAbilities{}
+	}
+	return group.Abilities
+}
+
+// WaveDefinitionFor returns the definition of a wave level for a run type.
+// The seed only matters for endless runs.
+func WaveDefinitionFor(runType string, seed int64, level int) WaveDefinition {
+	if runType == EndlessRun {
+		return GenerateEndlessWave(seed, level)
+	}
+	return waveSet.Definition(level)
+}
+
+// EndlessBudget returns the point budget of an endless wave
+func EndlessBudget(level int) float64 {
+	return endlessBaseBudget * math.Pow(endlessBudgetGrowth, float64(level-1))
+}
+
+// GenerateEndlessWave generates the definition of an endless wave.
+// The same seed and level always produce the same wave.
+func GenerateEndlessWave(seed int64, level int) WaveDefinition {
+	if level < 1 {
+		level = 1
+	}
+
+	rng := rand.New(rand.NewSource(seed*1_000_003 + int64(level)))
+	budget := EndlessBudget(level)
+	def := WaveDefinition{Level: level}
+
+	// Every tenth wave a boss takes a share of the budget
+	if level%endlessBossEvery == 0 && len(bossSet.Bosses) > 0 {
+		boss := bossSet.Bosses[rng.Intn(len(bossSet.Bosses))]
+		def.Bosses = append(def.Bosses, WaveBoss{
+			BossID:           boss.ID,
+			Delay:            endlessGroupSpacing,
+			HealthMultiplier: EndlessBudget(level) / EndlessBudget(endlessBossEvery),
+		})
+		budget *= 0.7
+	}
+
+	// Enemy types unlocked at this level, in a stable order so the seed decides the picks
+	var available []string
+	for enemyType, enemy := range endlessEnemies {
+		if enemy.MinLevel <= level {
+			available = append(available, enemyType)
+		}
+	}
+	sort.Strings(available)
+
+	remaining := budget
+	spent := 0.0
+	var delay int64
+	for len(def.Groups) < endlessMaxGroups {
+		enemyType := available[rng.Intn(len(available))]
+		enemy := endlessEnemies[enemyType]
+		group := WaveGroup{
+			EnemyType: enemyType,
+			Interval:  enemy.Interval,
+			Delay:     delay,
+		}
+
+		// Buy up to two upgrades, each one raising the price of every enemy in the group
+		unitCost := enemy.Cost
+		for i := 0; i < 2; i++ {
+			upgrade := endlessUpgrades[rng.Intn(len(endlessUpgrades))]
+			if upgrade.MinLevel > level || rng.Float64() < 0.5 {
+				continue
+			}
+			upgrade.Apply(&group)
+			unitCost *= upgrade.CostMultiplier
+		}
+
+		count := min(int(remaining/unitCost), endlessMinGroupSize+rng.Intn(endlessMaxGroupSize-endlessMinGroupSize+1))
+		if count < 1 {
+			break
+		}
+
+		group.Count = count
+		def.Groups = append(def.Groups, group)
+		remaining -= float64(count) * unitCost
+		spent += float64(count) * unitCost
+		delay += int64(count)*group.Interval + endlessGroupSpacing
+	}
+
+	// Spend what's left on health once the wave can't hold more enemies
+	if spent > 0 && remaining > 0 {
+		bonus := 1 + remaining/spent
+		for i := range def.Groups {
+			def.Groups[i].HealthMultiplier = orOne(def.Groups[i].HealthMultiplier) * bonus
+		}
+	}
+
+	return def
+}
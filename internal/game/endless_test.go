@@ -0,0 +1,74 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+
+	"realtime-game-backend/internal/models"
+)
+
+// endlessHealth returns the total health of a wave's groups, before level scaling
+func endlessHealth(def WaveDefinition) float64 {
+	types := models.GetEnemyTypes()
+	total := 0.0
+	for _, group := range def.Groups {
+		total += float64(group.Count*types[group.EnemyType].Health) * orOne(group.HealthMultiplier)
+	}
+	return total
+}
+
+func TestGenerateEndlessWaveIsDeterministic(t *testing.T) {
+	differs := false
+	for level := 1; level <= 30; level++ {
+		first := GenerateEndlessWave(42, level)
+		if again := GenerateEndlessWave(42, level); !reflect.DeepEqual(first, again) {
+			t.Fatalf("seed 42 generated two different waves at level %d:\n%+v\n%+v", level, first, again)
+		}
+		if !reflect.DeepEqual(first, GenerateEndlessWave(7, level)) {
+			differs = true
+		}
+	}
+
+	if !differs {
+		t.Fatal("seeds 42 and 7 generated the same run")
+	}
+}
+
+func TestEndlessBudgetGrows(t *testing.T) {
+	if budget := EndlessBudget(1); budget != endlessBaseBudget {
+		t.Fatalf("first wave has a budget of %v, want %v", budget, endlessBaseBudget)
+	}
+	for level := 2; level <= 50; level++ {
+		if EndlessBudget(level) <= EndlessBudget(level-1) {
+			t.Fatalf("budget of wave %d isn't above wave %d", level, level-1)
+		}
+	}
+
+	// Averaged over seeds, the budget buys ever tougher waves. Boss waves are skipped,
+	// since their boss takes a share of the budget.
+	const seeds = 20
+	previous := 0.0
+	for _, level := range []int{1, 5, 9, 15, 25, 35} {
+		total := 0.0
+		for seed := int64(0); seed < seeds; seed++ {
+			total += endlessHealth(GenerateEndlessWave(seed, level))
+		}
+		average := total / seeds
+		if average <= previous {
+			t.Fatalf("wave %d averages %v health, no more than the wave before it (%v)", level, average, previous)
+		}
+		previous = average
+	}
+}
+
+func TestGenerateEndlessWaveBosses(t *testing.T) {
+	for level := 1; level <= 30; level++ {
+		def := GenerateEndlessWave(42, level)
+		if hasBoss := len(def.Bosses) > 0; hasBoss != (level%endlessBossEvery == 0) {
+			t.Fatalf("wave %d has a boss: %v", level, hasBoss)
+		}
+		if len(def.Groups) == 0 || len(def.Groups) > endlessMaxGroups {
+			t.Fatalf("wave %d has %d groups", level, len(def.Groups))
+		}
+	}
+}
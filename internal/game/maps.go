@@ -0,0 +1,403 @@
+package game
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"realtime-game-backend/internal/models"
+)
+
+// Error definitions
+var (
+	ErrUnknownMap       = errors.New("unknown map")
+	ErrUnbuildableTile  = errors.New("towers can't be built on this tile")
+	ErrOutsideMapBounds = errors.New("position is outside the map")
+)
+
+// DefaultMapID is the map used by rooms that haven't selected one
+const DefaultMapID = "classic"
+
+// Tile markers in a map's tile rows
+const (
+	buildableTile   = '.'
+	unbuildableTile = '#'
+)
+
+// defaultMaps holds the maps shipped with the server
+//
+//go:embed data/maps/*.json
+var defaultMaps embed.FS
+
+// mapRegistry maps map IDs to their definitions
+var mapRegistry = make(map[string]*MapDefinition)
+
+func init() {
+	files, err := defaultMaps.ReadDir("data/maps")
+	if err != nil {
+		panic(fmt.Sprintf("reading default maps: %v", err))
+	}
+
+	for _, file := range files {
+		data, err := defaultMaps.ReadFile("data/maps/" + file.Name())
+		if err != nil {
+			panic(fmt.Sprintf("reading default map %s: %v", file.Name(), err))
+		}
+
+		m, err := ParseMap(data)
+		if err != nil {
+			panic(fmt.Sprintf("invalid default map %s: %v", file.Name(), err))
+		}
+		RegisterMap(m)
+	}
+
+	if _, ok := mapRegistry[DefaultMapID]; !ok {
+		panic(fmt.Sprintf("default map %q is missing", DefaultMapID))
+	}
+}
+
+// TilePoint represents a tile coordinate on a map
+type TilePoint struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// MapSpawn represents a point where enemies enter the map
+type MapSpawn struct {
+	ID string `json:"id"`
+	X  int    `json:"x"`
+	Y  int    `json:"y"`
+}
+
+// MapLane represents a route from a spawn to the base.
+// Lanes branch and merge by sharing waypoints with other lanes.
+type MapLane struct {
+	ID        string      `json:"id"`
+	Spawn     string      `json:"spawn"`     // ID of the spawn the lane starts from
+	Waypoints []TilePoint `json:"waypoints"` // Turns between the spawn and the base, joined by straight lines
+}
+
+// MapDecoration represents a decorative blocker that towers can't be built on
+type MapDecoration struct {
+	Type   string `json:"type"` // "rock", "tree", "water", etc.
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// MapDefinition describes the layout of a map
+type MapDefinition struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Width       int             `json:"width"`    // Width in tiles
+	Height      int             `json:"height"`   // Height in tiles
+	TileSize    float64         `json:"tileSize"` // Tile size in pixels
+	Spawns      []MapSpawn      `json:"spawns"`
+	Lanes       []MapLane       `json:"lanes"`
+	Base        TilePoint       `json:"base"`
+	Tiles       []string        `json:"tiles,omitempty"` // One row per line, '.' buildable and '#' unbuildable. All tiles are buildable if omitted
+	Decorations []MapDecoration `json:"decorations,omitempty"`
+
+	// obstacles marks unbuildable tiles and decorations, indexed by y*Width+x
+	obstacles []bool
+
+	// blocked marks the tiles towers can't be built on: obstacles, lanes, spawns and the base
+	blocked []bool
+}
+
+// ParseMap parses and validates a JSON map definition
+func ParseMap(data []byte) (*MapDefinition, error) {
+	var m MapDefinition
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	m.obstacles = m.obstacleTiles()
+	m.blocked = m.blockedTiles()
+	return &m, nil
+}
+
+// LoadMaps loads and validates every JSON map in a directory
+func LoadMaps(dir string) ([]*MapDefinition, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var maps []*MapDefinition
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		m, err := ParseMap(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		maps = append(maps, m)
+	}
+
+	return maps, nil
+}
+
+// Validate checks that the map is consistent and its lanes are made of straight segments
+func (m *MapDefinition) Validate() error {
+	if m.ID == "" {
+		return errors.New("map is missing an id")
+	}
+	if m.Width <= 0 || m.Height <= 0 || m.TileSize <= 0 {
+		return fmt.Errorf("map %q: needs a positive width, height and tile size", m.ID)
+	}
+	if !m.inBounds(m.Base) {
+		return fmt.Errorf("map %q: base is outside the map", m.ID)
+	}
+
+	spawns := make(map[string]TilePoint)
+	for _, spawn := range m.Spawns {
+		point := TilePoint{X: spawn.X, Y: spawn.Y}
+		if !m.inBounds(point) {
+			return fmt.Errorf("map %q: spawn %q is outside the map", m.ID, spawn.ID)
+		}
+		if _, ok := spawns[spawn.ID]; ok {
+			return fmt.Errorf("map %q: duplicate spawn %q", m.ID, spawn.ID)
+		}
+		spawns[spawn.ID] = point
+	}
+	if len(spawns) == 0 {
+		return fmt.Errorf("map %q: needs at least one spawn", m.ID)
+	}
+
+	if len(m.Lanes) == 0 {
+		return fmt.Errorf("map %q: needs at least one lane", m.ID)
+	}
+	for _, lane := range m.Lanes {
+		start, ok := spawns[lane.Spawn]
+		if !ok {
+			return fmt.Errorf("map %q: lane %q starts at unknown spawn %q", m.ID, lane.ID, lane.Spawn)
+		}
+
+		points := m.lanePoints(lane, start)
+		for i, point := range points {
+			if !m.inBounds(point) {
+				return fmt.Errorf("map %q: lane %q leaves the map", m.ID, lane.ID)
+			}
+			if i > 0 && point.X != points[i-1].X && point.Y != points[i-1].Y {
+				return fmt.Errorf("map %q: lane %q has a diagonal segment", m.ID, lane.ID)
+			}
+		}
+	}
+
+	if len(m.Tiles) > 0 {
+		if len(m.Tiles) != m.Height {
+			return fmt.Errorf("map %q: needs %d tile rows", m.ID, m.Height)
+		}
+		for y, row := range m.Tiles {
+			if len(row) != m.Width {
+				return fmt.Errorf("map %q: tile row %d needs %d tiles", m.ID, y, m.Width)
+			}
+			for _, tile := range row {
+				if tile != buildableTile && tile != unbuildableTile {
+					return fmt.Errorf("map %q: tile row %d has unknown tile %q", m.ID, y, tile)
+				}
+			}
+		}
+	}
+
+	for _, decoration := range m.Decorations {
+		if decoration.Width <= 0 || decoration.Height <= 0 {
+			return fmt.Errorf("map %q: %s decoration needs a positive size", m.ID, decoration.Type)
+		}
+		corner := TilePoint{X: decoration.X + decoration.Width - 1, Y: decoration.Y + decoration.Height - 1}
+		if !m.inBounds(TilePoint{X: decoration.X, Y: decoration.Y}) || !m.inBounds(corner) {
+			return fmt.Errorf("map %q: %s decoration is outside the map", m.ID, decoration.Type)
+		}
+	}
+
+	return nil
+}
+
+// inBounds checks if a tile is on the map
+func (m *MapDefinition) inBounds(p TilePoint) bool {
+	return p.X >= 0 && p.Y >= 0 && p.X < m.Width && p.Y < m.Height
+}
+
+// lanePoints returns every turn of a lane, from its spawn to the base
+func (m *MapDefinition) lanePoints(lane MapLane, start TilePoint) []TilePoint {
+	points := make([]TilePoint, 0, len(lane.Waypoints)+2)
+	points = append(points, start)
+	points = append(points, lane.Waypoints...)
+	return append(points, m.Base)
+}
+
+// obstacleTiles marks the unbuildable tiles and decorations
+func (m *MapDefinition) obstacleTiles() []bool {
+	obstacles := make([]bool, m.Width*m.Height)
+
+	for y, row := range m.Tiles {
+		for x, tile := range row {
+			if tile == unbuildableTile {
+				obstacles[y*m.Width+x] = true
+			}
+		}
+	}
+
+	for _, decoration := range m.Decorations {
+		for y := decoration.Y; y < decoration.Y+decoration.Height; y++ {
+			for x := decoration.X; x < decoration.X+decoration.Width; x++ {
+				obstacles[y*m.Width+x] = true
+			}
+		}
+	}
+
+	return obstacles
+}
+
+// blockedTiles marks the obstacles and the tiles covered by lanes, spawns and the base
+func (m *MapDefinition) blockedTiles() []bool {
+	blocked := make([]bool, len(m.obstacles))
+	copy(blocked, m.obstacles)
+	block := func(p TilePoint) {
+		blocked[p.Y*m.Width+p.X] = true
+	}
+
+	block(m.Base)
+	spawns := make(map[string]TilePoint)
+	for _, spawn := range m.Spawns {
+		spawns[spawn.ID] = TilePoint{X: spawn.X, Y: spawn.Y}
+		block(spawns[spawn.ID])
+	}
+
+	// Block every tile along each straight lane segment
+	for _, lane := range m.Lanes {
+		points := m.lanePoints(lane, spawns[lane.Spawn])
+		for i := 1; i < len(points); i++ {
+			from, to := points[i-1], points[i]
+			for x := min(from.X, to.X); x <= max(from.X, to.X); x++ {
+				for y := min(from.Y, to.Y); y <= max(from.Y, to.Y); y++ {
+					block(TilePoint{X: x, Y: y})
+				}
+			}
+		}
+	}
+
+	return blocked
+}
+
+// TileCenter returns the pixel position of the center of a tile
+func (m *MapDefinition) TileCenter(p TilePoint) models.Point {
+	return models.Point{
+		X: (float64(p.X) + 0.5) * m.TileSize,
+		Y: (float64(p.Y) + 0.5) * m.TileSize,
+	}
+}
+
+// TileAt returns the tile containing a pixel position
+func (m *MapDefinition) TileAt(x, y float64) (TilePoint, bool) {
+	if x < 0 || y < 0 {
+		return TilePoint{}, false
+	}
+	p := TilePoint{X: int(x / m.TileSize), Y: int(y / m.TileSize)}
+	return p, m.inBounds(p)
+}
+
+// CheckBuildable checks that a tower can be built at a pixel position
+func (m *MapDefinition) CheckBuildable(x, y float64) error {
+	tile, ok := m.TileAt(x, y)
+	if !ok {
+		return ErrOutsideMapBounds
+	}
+	if m.blocked[tile.Y*m.Width+tile.X] {
+		return ErrUnbuildableTile
+	}
+	return nil
+}
+
+// Buildable returns whether each tile can be built on in a game mode, as rows of '.' and '#'.
+// In maze mode the lanes are open ground.
+func (m *MapDefinition) Buildable(mode string) []string {
+	blocked := m.blocked
+	if mode == MazeMode {
+		blocked = make([]bool, len(m.obstacles))
+		copy(blocked, m.obstacles)
+		blocked[m.Base.Y*m.Width+m.Base.X] = true
+		for _, spawn := range m.Spawns {
+			blocked[spawn.Y*m.Width+spawn.X] = true
+		}
+	}
+
+	rows := make([]string, m.Height)
+	for y := range rows {
+		row := make([]byte, m.Width)
+		for x := range row {
+			row[x] = buildableTile
+			if blocked[y*m.Width+x] {
+				row[x] = unbuildableTile
+			}
+		}
+		rows[y] = string(row)
+	}
+	return rows
+}
+
+// LanePaths returns the pixel path of every lane, in lane order
+func (m *MapDefinition) LanePaths() []models.Lane {
+	spawns := make(map[string]TilePoint)
+	for _, spawn := range m.Spawns {
+		spawns[spawn.ID] = TilePoint{X: spawn.X, Y: spawn.Y}
+	}
+
+	lanes := make([]models.Lane, len(m.Lanes))
+	for i, lane := range m.Lanes {
+		points := m.lanePoints(lane, spawns[lane.Spawn])
+		path := make([]models.Point, len(points))
+		for j, point := range points {
+			path[j] = m.TileCenter(point)
+		}
+
+		lanes[i] = models.Lane{
+			ID:         lane.ID,
+			Path:       path,
+			FlyingPath: shortcutPath(path),
+		}
+	}
+
+	return lanes
+}
+
+// RegisterMap adds a map to the registry, replacing any map with the same ID
+func RegisterMap(m *MapDefinition) {
+	mapRegistry[m.ID] = m
+}
+
+// GetMap returns a map from the registry
+func GetMap(mapID string) (*MapDefinition, error) {
+	m, ok := mapRegistry[mapID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownMap, mapID)
+	}
+	return m, nil
+}
+
+// ListMaps returns every registered map, sorted by ID
+func ListMaps() []*MapDefinition {
+	maps := make([]*MapDefinition, 0, len(mapRegistry))
+	for _, m := range mapRegistry {
+		maps = append(maps, m)
+	}
+
+	sort.Slice(maps, func(i, j int) bool {
+		return maps[i].ID < maps[j].ID
+	})
+
+	return maps
+}
@@ -0,0 +1,136 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"realtime-game-backend/internal/models"
+)
+
+// testMapJSON is a 5x5 map with a lane from the north and one from the west meeting at the base in the south-east corner,
+// a blocked tile in the north-east corner and a rock in the north-west corner
+const testMapJSON = `{
+	"id": "test-lanes",
+	"width": 5,
+	"height": 5,
+	"tileSize": 100,
+	"spawns": [{"id": "north", "x": 2, "y": 0}, {"id": "west", "x": 0, "y": 2}],
+	"lanes": [
+		{"id": "north-lane", "spawn": "north", "waypoints": [{"x": 2, "y": 4}]},
+		{"id": "west-lane", "spawn": "west", "waypoints": [{"x": 0, "y": 4}]}
+	],
+	"base": {"x": 4, "y": 4},
+	"tiles": ["....#", ".....", ".....", ".....", "....."],
+	"decorations": [{"type": "rock", "x": 0, "y": 0, "width": 1, "height": 1}]
+}`
+
+// testLaneMap parses the test map
+func testLaneMap(t *testing.T) *MapDefinition {
+	t.Helper()
+
+	m, err := ParseMap([]byte(testMapJSON))
+	if err != nil {
+		t.Fatalf("parsing test map: %v", err)
+	}
+	return m
+}
+
+func TestMapValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(m *MapDefinition)
+		want   string // Part of the error
+	}{
+		{"missing id", func(m *MapDefinition) { m.ID = "" }, "missing an id"},
+		{"no tile size", func(m *MapDefinition) { m.TileSize = 0 }, "tile size"},
+		{"base outside", func(m *MapDefinition) { m.Base = TilePoint{X: 5, Y: 4} }, "base"},
+		{"spawn outside", func(m *MapDefinition) { m.Spawns[0].Y = -1 }, "outside the map"},
+		{"duplicate spawn", func(m *MapDefinition) { m.Spawns[1].ID = "north" }, "duplicate spawn"},
+		{"no spawns", func(m *MapDefinition) { m.Spawns = nil }, "spawn"},
+		{"no lanes", func(m *MapDefinition) { m.Lanes = nil }, "lane"},
+		{"unknown spawn", func(m *MapDefinition) { m.Lanes[0].Spawn = "east" }, "unknown spawn"},
+		{"lane leaves the map", func(m *MapDefinition) { m.Lanes[0].Waypoints[0] = TilePoint{X: 2, Y: 7} }, "leaves the map"},
+		{"diagonal lane", func(m *MapDefinition) { m.Lanes[1].Waypoints[0] = TilePoint{X: 1, Y: 3} }, "diagonal"},
+		{"missing tile rows", func(m *MapDefinition) { m.Tiles = m.Tiles[:4] }, "tile rows"},
+		{"short tile row", func(m *MapDefinition) { m.Tiles[2] = "...." }, "tile row 2"},
+		{"unknown tile", func(m *MapDefinition) { m.Tiles[3] = "..~.." }, "unknown tile"},
+		{"empty decoration", func(m *MapDefinition) { m.Decorations[0].Width = 0 }, "positive size"},
+		{"decoration outside", func(m *MapDefinition) { m.Decorations[0].X, m.Decorations[0].Width = 4, 2 }, "decoration is outside"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testLaneMap(t)
+			tt.modify(m)
+
+			err := m.Validate()
+			if err == nil {
+				t.Fatal("invalid map was accepted")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got error %q, want one about %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckBuildable(t *testing.T) {
+	m := testLaneMap(t)
+
+	tests := []struct {
+		name string
+		x, y float64
+		want error
+	}{
+		{"open ground", 150, 150, nil},
+		{"between the lanes", 150, 350, nil},
+		{"north lane", 250, 150, ErrUnbuildableTile},
+		{"lane corner", 250, 450, ErrUnbuildableTile},
+		{"west lane", 50, 350, ErrUnbuildableTile},
+		{"last stretch", 350, 450, ErrUnbuildableTile},
+		{"spawn", 250, 50, ErrUnbuildableTile},
+		{"base", 450, 450, ErrUnbuildableTile},
+		{"blocked tile", 450, 50, ErrUnbuildableTile},
+		{"decoration", 50, 50, ErrUnbuildableTile},
+		{"left of the map", -1, 150, ErrOutsideMapBounds},
+		{"right of the map", 500, 150, ErrOutsideMapBounds},
+		{"below the map", 150, 520, ErrOutsideMapBounds},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := m.CheckBuildable(tt.x, tt.y); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLanePaths(t *testing.T) {
+	lanes := testLaneMap(t).LanePaths()
+	if len(lanes) != 2 {
+		t.Fatalf("got %d lanes, want 2", len(lanes))
+	}
+
+	want := map[string][]models.Point{
+		"north-lane": {{X: 250, Y: 50}, {X: 250, Y: 450}, {X: 450, Y: 450}},
+		"west-lane":  {{X: 50, Y: 250}, {X: 50, Y: 450}, {X: 450, Y: 450}},
+	}
+	for i, id := range []string{"north-lane", "west-lane"} {
+		lane := lanes[i]
+		if lane.ID != id {
+			t.Fatalf("lane %d is %q, want %q", i, lane.ID, id)
+		}
+		if fmt.Sprint(lane.Path) != fmt.Sprint(want[id]) {
+			t.Errorf("lane %s follows %v, want %v", id, lane.Path, want[id])
+		}
+
+		// Flyers take the same start and end
+		flying := lane.FlyingPath
+		if flying[0] != lane.Path[0] || flying[len(flying)-1] != lane.Path[len(lane.Path)-1] {
+			t.Errorf("lane %s flying path %v doesn't join the spawn and the base", id, flying)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+
+	"realtime-game-backend/internal/models"
+)
+
+// Game modes
+const (
+	LanesMode = "lanes" // Enemies follow the map's fixed lanes
+	MazeMode  = "maze"  // Enemies pathfind around towers from each spawn to the base
+)
+
+// Error definitions
+var (
+	ErrUnknownMode    = errors.New("unknown game mode")
+	ErrMazeBlocked    = errors.New("placement would block every route to the base")
+	ErrTileOccupied   = errors.New("tile already has a tower")
+	ErrMazeUnsuitable = errors.New("map has a spawn on its base and can't be played as a maze")
+)
+
+// ValidateMode checks that a game mode exists and can be played on a map
+func ValidateMode(mode string, m *MapDefinition) error {
+	switch mode {
+	case LanesMode:
+		return nil
+	case MazeMode:
+		for _, spawn := range m.Spawns {
+			if spawn.X == m.Base.X && spawn.Y == m.Base.Y {
+				return fmt.Errorf("%w: %q", ErrMazeUnsuitable, m.ID)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownMode, mode)
+	}
+}
+
+// SnapToTile returns the center of the tile containing a pixel position
+func (m *MapDefinition) SnapToTile(x, y float64) (models.Point, error) {
+	tile, ok := m.TileAt(x, y)
+	if !ok {
+		return models.Point{}, ErrOutsideMapBounds
+	}
+	return m.TileCenter(tile), nil
+}
+
+// mazeWalls marks the tiles enemies can't walk through: obstacles and towers
+func mazeWalls(m *MapDefinition, towers []models.Tower) []bool {
+	walls := make([]bool, len(m.obstacles))
+	copy(walls, m.obstacles)
+
+	for _, tower := range towers {
+		if tile, ok := m.TileAt(tower.X, tower.Y); ok {
+			walls[tile.Y*m.Width+tile.X] = true
+		}
+	}
+
+	return walls
+}
+
+// CheckMazePlacement checks that a tower can be built at a pixel position in maze mode.
+// The tile must be free, and every spawn must still be able to reach the base afterwards.
+func CheckMazePlacement(m *MapDefinition, towers []models.Tower, x, y float64) error {
+	tile, ok := m.TileAt(x, y)
+	if !ok {
+		return ErrOutsideMapBounds
+	}
+
+	index := tile.Y*m.Width + tile.X
+	if m.obstacles[index] || tile == m.Base {
+		return ErrUnbuildableTile
+	}
+	for _, spawn := range m.Spawns {
+		if tile.X == spawn.X && tile.Y == spawn.Y {
+			return ErrUnbuildableTile
+		}
+	}
+
+	walls := mazeWalls(m, towers)
+	if walls[index] {
+		return ErrTileOccupied
+	}
+
+	// Try the placement and make sure no spawn is cut off
+	walls[index] = true
+	distances := distanceField(m, walls, m.Base)
+	for _, spawn := range m.Spawns {
+		if distances[spawn.Y*m.Width+spawn.X] < 0 {
+			return ErrMazeBlocked
+		}
+	}
+
+	return nil
+}
+
+// MazeLanes builds one lane per spawn by pathfinding around the towers.
+// Flying enemies ignore the maze and fly straight to the base.
+func MazeLanes(m *MapDefinition, towers []models.Tower) ([]models.Lane, error) {
+	walls := mazeWalls(m, towers)
+	return mazeLanes(m, distanceField(m, walls, m.Base))
+}
+
+// mazeLanes builds one lane per spawn from the distances of every tile to the base
+func mazeLanes(m *MapDefinition, distances []int) ([]models.Lane, error) {
+	base := m.TileCenter(m.Base)
+
+	lanes := make([]models.Lane, len(m.Spawns))
+	for i, spawn := range m.Spawns {
+		start := TilePoint{X: spawn.X, Y: spawn.Y}
+		tiles, ok := followDistances(m, distances, start)
+		if !ok {
+			return nil, fmt.Errorf("%w: spawn %q", ErrMazeBlocked, spawn.ID)
+		}
+
+		lanes[i] = models.Lane{
+			ID:         spawn.ID,
+			Path:       m.tilePath(tiles),
+			FlyingPath: []models.Point{m.TileCenter(start), base},
+		}
+	}
+
+	return lanes, nil
+}
+
+// CreateMazeWave creates the enemy wave described by a wave definition in maze mode
+func CreateMazeWave(def WaveDefinition, m *MapDefinition, towers []models.Tower) (models.EnemyWave, error) {
+	lanes, err := MazeLanes(m, towers)
+	if err != nil {
+		return models.EnemyWave{}, err
+	}
+
+	wave := newWave(def.Level, lanes)
+	wave.Enemies = buildEnemies(wave.ID, def, waveSet.Scaling, lanes)
+	return wave, nil
+}
+
+// RerouteMaze recalculates the lanes of a maze wave and gives every walking enemy a new path from where it stands.
+// Enemies cut off from the base keep their current path.
+func RerouteMaze(wave models.EnemyWave, m *MapDefinition, towers []models.Tower) models.EnemyWave {
+	// One search from the base serves every enemy, however many are on the field
+	distances := distanceField(m, mazeWalls(m, towers), m.Base)
+
+	if lanes, err := mazeLanes(m, distances); err == nil {
+		wave.Lanes = lanes
+		wave.Path = lanes[0].Path
+		wave.FlyingPath = lanes[0].FlyingPath
+	}
+
+	for i, enemy := range wave.Enemies {
+		if !enemy.Active || enemy.Abilities.Flying {
+			continue
+		}
+
+		tile, ok := m.TileAt(enemy.X, enemy.Y)
+		if !ok {
+			continue
+		}
+
+		tiles, ok := followDistances(m, distances, tile)
+		if !ok {
+			continue
+		}
+
+		// Start from the enemy's exact position so it doesn't jump
+		position := models.Point{X: enemy.X, Y: enemy.Y}
+		wave.Enemies[i].Path = append([]models.Point{position}, m.tilePath(tiles)...)
+		wave.Enemies[i].PathIndex = 0
+	}
+
+	return wave
+}
+
+// tilePath converts a tile route into pixel waypoints, keeping only the turns
+func (m *MapDefinition) tilePath(tiles []TilePoint) []models.Point {
+	path := []models.Point{m.TileCenter(tiles[0])}
+	for i := 1; i < len(tiles)-1; i++ {
+		prev, next := tiles[i-1], tiles[i+1]
+		if prev.X == next.X || prev.Y == next.Y {
+			continue // Straight through, not a turn
+		}
+		path = append(path, m.TileCenter(tiles[i]))
+	}
+	if len(tiles) > 1 {
+		path = append(path, m.TileCenter(tiles[len(tiles)-1]))
+	}
+	return path
+}
+
+// tileSteps are the moves between neighbouring tiles, in the order ties are broken
+var tileSteps = [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+
+// distanceField returns the number of steps from every tile to a target tile, found with a
+// breadth-first search from the target. Walls and tiles that can't reach the target are -1.
+func distanceField(m *MapDefinition, walls []bool, to TilePoint) []int {
+	distances := make([]int, len(walls))
+	for i := range distances {
+		distances[i] = -1
+	}
+
+	goal := to.Y*m.Width + to.X
+	distances[goal] = 0
+	queue := []int{goal}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		x, y := current%m.Width, current/m.Width
+		for _, step := range tileSteps {
+			nx, ny := x+step[0], y+step[1]
+			if nx < 0 || ny < 0 || nx >= m.Width || ny >= m.Height {
+				continue
+			}
+
+			next := ny*m.Width + nx
+			if walls[next] || distances[next] >= 0 {
+				continue
+			}
+			distances[next] = distances[current] + 1
+			queue = append(queue, next)
+		}
+	}
+
+	return distances
+}
+
+// followDistances builds the route from a tile to the target of a distance field by stepping
+// to a neighbour one step closer each time. A start tile inside a wall steps to its closest neighbour.
+func followDistances(m *MapDefinition, distances []int, from TilePoint) ([]TilePoint, bool) {
+	route := []TilePoint{from}
+	current := from.Y*m.Width + from.X
+	for distances[current] != 0 {
+		best := -1
+		x, y := current%m.Width, current/m.Width
+		for _, step := range tileSteps {
+			nx, ny := x+step[0], y+step[1]
+			if nx < 0 || ny < 0 || nx >= m.Width || ny >= m.Height {
+				continue
+			}
+
+			next := ny*m.Width + nx
+			if distances[next] < 0 {
+				continue
+			}
+			if best < 0 || distances[next] < distances[best] {
+				best = next
+			}
+		}
+
+		// Every step must get closer, which also rules out walking back into a wall
+		if best < 0 || (distances[current] >= 0 && distances[best] >= distances[current]) {
+			return nil, false
+		}
+
+		current = best
+		route = append(route, TilePoint{X: current % m.Width, Y: current / m.Width})
+	}
+
+	return route, true
+}
@@ -0,0 +1,228 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"realtime-game-backend/internal/models"
+)
+
+// testMazeMap returns an open maze map whose enemies walk from the west edge to the base on the east edge
+func testMazeMap(t testing.TB, width, height int) *MapDefinition {
+	t.Helper()
+
+	m, err := ParseMap([]byte(fmt.Sprintf(`{
+		"id": "test-maze",
+		"width": %d,
+		"height": %d,
+		"tileSize": 100,
+		"spawns": [{"id": "west", "x": 0, "y": 1}],
+		"lanes": [{"id": "row", "spawn": "west", "waypoints": [{"x": %d, "y": 1}]}],
+		"base": {"x": %d, "y": 1}
+	}`, width, height, width-1, width-1)))
+	if err != nil {
+		t.Fatalf("parsing test map: %v", err)
+	}
+	return m
+}
+
+// mazeTower returns a tower standing on a tile
+func mazeTower(m *MapDefinition, x, y int) models.Tower {
+	center := m.TileCenter(TilePoint{X: x, Y: y})
+	return models.Tower{ID: fmt.Sprintf("tower-%d-%d", x, y), X: center.X, Y: center.Y}
+}
+
+// checkRoute checks that a route is made of neighbouring tiles, avoids walls and ends on a tile
+func checkRoute(t *testing.T, m *MapDefinition, walls []bool, route []TilePoint, to TilePoint) {
+	t.Helper()
+
+	for i := 1; i < len(route); i++ {
+		dx, dy := route[i].X-route[i-1].X, route[i].Y-route[i-1].Y
+		if dx*dx+dy*dy != 1 {
+			t.Fatalf("route jumps from %v to %v", route[i-1], route[i])
+		}
+		if walls[route[i].Y*m.Width+route[i].X] {
+			t.Fatalf("route walks through the wall at %v", route[i])
+		}
+	}
+	if end := route[len(route)-1]; end != to {
+		t.Fatalf("route ends at %v, want %v", end, to)
+	}
+}
+
+func TestFollowDistances(t *testing.T) {
+	m := testMazeMap(t, 5, 3)
+	start := TilePoint{X: 0, Y: 1}
+
+	tests := []struct {
+		name   string
+		towers []TilePoint
+		from   TilePoint
+		length int // Tiles in the route, 0 if the base can't be reached
+	}{
+		{"straight", nil, start, 5},
+		{"detour", []TilePoint{{2, 1}}, start, 7},
+		{"gap at the edge", []TilePoint{{2, 0}, {2, 1}}, start, 7},
+		{"out of a wall", []TilePoint{{2, 1}}, TilePoint{X: 2, Y: 1}, 3},
+		{"cut off", []TilePoint{{2, 0}, {2, 1}, {2, 2}}, start, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var towers []models.Tower
+			for _, tile := range tt.towers {
+				towers = append(towers, mazeTower(m, tile.X, tile.Y))
+			}
+			walls := mazeWalls(m, towers)
+
+			route, ok := followDistances(m, distanceField(m, walls, m.Base), tt.from)
+			if tt.length == 0 {
+				if ok {
+					t.Fatalf("found route %v through a closed maze", route)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("found no route")
+			}
+			if len(route) != tt.length {
+				t.Fatalf("got a route of %d tiles, want %d: %v", len(route), tt.length, route)
+			}
+			if route[0] != tt.from {
+				t.Fatalf("route starts at %v, want %v", route[0], tt.from)
+			}
+			checkRoute(t, m, walls, route, m.Base)
+		})
+	}
+}
+
+func TestCheckMazePlacement(t *testing.T) {
+	m := testMazeMap(t, 5, 3)
+	towers := []models.Tower{mazeTower(m, 2, 0), mazeTower(m, 2, 1)}
+
+	tests := []struct {
+		name string
+		x, y float64
+		want error
+	}{
+		{"free tile", 350, 50, nil},
+		{"last gap", 250, 250, ErrMazeBlocked},
+		{"occupied", 250, 150, ErrTileOccupied},
+		{"base", 450, 150, ErrUnbuildableTile},
+		{"spawn", 50, 150, ErrUnbuildableTile},
+		{"outside", 550, 150, ErrOutsideMapBounds},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckMazePlacement(m, towers, tt.x, tt.y); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRerouteMaze(t *testing.T) {
+	m := testMazeMap(t, 5, 3)
+	wave, err := CreateMazeWave(WaveDefinition{
+		Level:  1,
+		Groups: []WaveGroup{{EnemyType: "basic", Count: 3}},
+	}, m, nil)
+	if err != nil {
+		t.Fatalf("creating wave: %v", err)
+	}
+
+	// A walker in the middle of the map, a flyer, and a walker that hasn't spawned yet
+	walker := &wave.Enemies[0]
+	walker.Active, walker.X, walker.Y = true, 130, 160
+	flyer := &wave.Enemies[1]
+	flyer.Active, flyer.Abilities.Flying = true, true
+	flyerPath := flyer.Path
+	waiting := wave.Enemies[2]
+
+	towers := []models.Tower{mazeTower(m, 2, 1)}
+	wave = RerouteMaze(wave, m, towers)
+	walls := mazeWalls(m, towers)
+
+	// The lanes go around the tower
+	lane := wave.Lanes[0].Path
+	for _, point := range lane {
+		if tile, _ := m.TileAt(point.X, point.Y); walls[tile.Y*m.Width+tile.X] {
+			t.Fatalf("lane %v turns inside the tower", lane)
+		}
+	}
+
+	// The walker sets off from where it stands and reaches the base around the tower
+	path := wave.Enemies[0].Path
+	if path[0] != (models.Point{X: 130, Y: 160}) {
+		t.Fatalf("walker's path starts at %v, not where it stands", path[0])
+	}
+	if end := path[len(path)-1]; end != m.TileCenter(m.Base) {
+		t.Fatalf("walker's path ends at %v, not the base", end)
+	}
+	if wave.Enemies[0].PathIndex != 0 {
+		t.Fatalf("walker's path index is %d, want 0", wave.Enemies[0].PathIndex)
+	}
+	if len(path) < 4 {
+		t.Fatalf("walker's path %v goes straight through the tower", path)
+	}
+
+	// Flyers and enemies still waiting to spawn are left alone
+	if fmt.Sprint(wave.Enemies[1].Path) != fmt.Sprint(flyerPath) {
+		t.Fatal("flyer was rerouted")
+	}
+	if fmt.Sprint(wave.Enemies[2].Path) != fmt.Sprint(waiting.Path) {
+		t.Fatal("enemy waiting to spawn was rerouted")
+	}
+}
+
+func TestRerouteMazeKeepsPathsOfCutOffEnemies(t *testing.T) {
+	m := testMazeMap(t, 5, 3)
+	wave, err := CreateMazeWave(WaveDefinition{
+		Level:  1,
+		Groups: []WaveGroup{{EnemyType: "basic", Count: 1}},
+	}, m, nil)
+	if err != nil {
+		t.Fatalf("creating wave: %v", err)
+	}
+	wave.Enemies[0].Active = true
+	before := fmt.Sprint(wave.Enemies[0].Path)
+
+	// Placement checks never allow this, but the enemy must not be stranded without a path
+	towers := []models.Tower{mazeTower(m, 2, 0), mazeTower(m, 2, 1), mazeTower(m, 2, 2)}
+	wave = RerouteMaze(wave, m, towers)
+
+	if after := fmt.Sprint(wave.Enemies[0].Path); after != before {
+		t.Fatalf("cut off enemy's path changed from %s to %s", before, after)
+	}
+}
+
+// BenchmarkRerouteMaze measures rerouting an endless wave after a tower is placed
+func BenchmarkRerouteMaze(b *testing.B) {
+	m := testMazeMap(b, 40, 30)
+	wave, err := CreateMazeWave(WaveDefinition{
+		Level:  1,
+		Groups: []WaveGroup{{EnemyType: "basic", Count: 2000}},
+	}, m, nil)
+	if err != nil {
+		b.Fatalf("creating wave: %v", err)
+	}
+
+	// Spread the enemies over the map
+	for i := range wave.Enemies {
+		tile := TilePoint{X: i % m.Width, Y: (i / m.Width) % m.Height}
+		center := m.TileCenter(tile)
+		wave.Enemies[i].Active, wave.Enemies[i].X, wave.Enemies[i].Y = true, center.X, center.Y
+	}
+
+	var towers []models.Tower
+	for y := 0; y < m.Height-1; y++ {
+		towers = append(towers, mazeTower(m, m.Width/2, y))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		wave = RerouteMaze(wave, m, towers)
+	}
+}
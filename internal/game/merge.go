@@ -0,0 +1,89 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+
+	"realtime-game-backend/internal/models"
+)
+
+// Error definitions
+var (
+	ErrMergeTowerCount  = errors.New("merging needs two or three towers")
+	ErrMergeNotOwner    = errors.New("only the owner can merge a tower")
+	ErrMergeMismatch    = errors.New("merged towers must share a type and level")
+	ErrMergeNotAdjacent = errors.New("merged towers must be next to each other")
+	ErrMergeDuplicate   = errors.New("a tower can't be merged with itself")
+	ErrMergeMaxTier     = fmt.Errorf("%w: merged towers are already at the highest tier", ErrMaxLevel)
+)
+
+// mergeAdjacency is the maximum distance between the first tower and the others in a merge
+const mergeAdjacency = 60.0
+
+// Merge bonuses on top of a regular upgrade, by number of towers merged
+var mergeBonuses = map[int]float64{
+	2: 0.15, // +15%
+	3: 0.35, // +35%
+}
+
+// MergeTowers combines two or three identical towers into one tower of the next tier.
+// The merged tower takes the first tower's position and gains a bonus beyond a regular upgrade.
+// Like upgrades, merges stop at the catalog's maximum level for the tower type.
+func MergeTowers(playerID string, towers []models.Tower) (models.Tower, error) {
+	bonus, ok := mergeBonuses[len(towers)]
+	if !ok {
+		return models.Tower{}, ErrMergeTowerCount
+	}
+
+	first := towers[0]
+	seen := make(map[string]bool)
+	totalCost := 0
+
+	for _, tower := range towers {
+		if seen[tower.ID] {
+			return models.Tower{}, ErrMergeDuplicate
+		}
+		seen[tower.ID] = true
+
+		if tower.PlayerID != playerID {
+			return models.Tower{}, ErrMergeNotOwner
+		}
+
+		if tower.Type != first.Type || tower.Level != first.Level {
+			return models.Tower{}, ErrMergeMismatch
+		}
+
+		if distance(models.Point{X: first.X, Y: first.Y}, models.Point{X: tower.X, Y: tower.Y}) > mergeAdjacency {
+			return models.Tower{}, ErrMergeNotAdjacent
+		}
+
+		totalCost += tower.Cost
+	}
+
+	if first.Level >= towerCatalog.MaxLevel(first.Type) {
+		return models.Tower{}, ErrMergeMaxTier
+	}
+
+	// Start from a regular upgrade of the first tower
+	merged, err := UpgradeTower(first)
+	if err != nil {
+		return models.Tower{}, err
+	}
+	merged.ID = GenerateID()
+	merged.LastShot = 0
+
+	// Apply the merge bonus
+	multiplier := 1 + bonus
+	merged.Range *= multiplier
+	merged.Damage = int(float64(merged.Damage) * multiplier)
+	merged.Speed *= multiplier
+	merged.AuraRangeBonus *= multiplier
+	merged.AuraDamageBonus *= multiplier
+	merged.AuraSpeedBonus *= multiplier
+	merged.GoldPerWave = int(float64(merged.GoldPerWave) * multiplier)
+
+	// The merged tower is worth everything invested in its parts
+	merged.Cost = totalCost
+
+	return merged, nil
+}
@@ -0,0 +1,137 @@
+package game
+
+import (
+	"errors"
+	"testing"
+
+	"realtime-game-backend/internal/models"
+)
+
+// mergeTower creates a basic tower owned by a player at a position
+func mergeTower(t *testing.T, id, playerID string, x, y float64) models.Tower {
+	t.Helper()
+
+	tower, err := CreateTower(playerID, BasicTower, x, y)
+	if err != nil {
+		t.Fatalf("creating tower: %v", err)
+	}
+	tower.ID = id
+	return tower
+}
+
+func TestMergeTowersErrors(t *testing.T) {
+	a := mergeTower(t, "a", "alice", 150, 150)
+	b := mergeTower(t, "b", "alice", 200, 150)
+	c := mergeTower(t, "c", "alice", 150, 200)
+	d := mergeTower(t, "d", "alice", 200, 200)
+	far := mergeTower(t, "far", "alice", 400, 150)
+	bobs := mergeTower(t, "bobs", "bob", 200, 150)
+
+	upgraded, err := UpgradeTower(b)
+	if err != nil {
+		t.Fatalf("upgrading tower: %v", err)
+	}
+	sniper := b
+	sniper.Type = SniperTower
+
+	tests := []struct {
+		name   string
+		towers []models.Tower
+		want   error
+	}{
+		{"single tower", []models.Tower{a}, ErrMergeTowerCount},
+		{"four towers", []models.Tower{a, b, c, d}, ErrMergeTowerCount},
+		{"same tower twice", []models.Tower{a, a}, ErrMergeDuplicate},
+		{"another player's tower", []models.Tower{a, bobs}, ErrMergeNotOwner},
+		{"different level", []models.Tower{a, upgraded}, ErrMergeMismatch},
+		{"different type", []models.Tower{a, sniper}, ErrMergeMismatch},
+		{"too far apart", []models.Tower{a, far}, ErrMergeNotAdjacent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := MergeTowers("alice", tt.towers); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeTowersStats(t *testing.T) {
+	tests := []struct {
+		name  string
+		count int
+		bonus float64
+	}{
+		{"two towers", 2, 0.15},
+		{"three towers", 3, 0.35},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			positions := [][2]float64{{150, 150}, {200, 150}, {150, 200}}
+			towers := make([]models.Tower, tt.count)
+			totalCost := 0
+			for i := range towers {
+				towers[i] = mergeTower(t, string(rune('a'+i)), "alice", positions[i][0], positions[i][1])
+				totalCost += towers[i].Cost
+			}
+
+			merged, err := MergeTowers("alice", towers)
+			if err != nil {
+				t.Fatalf("merging: %v", err)
+			}
+
+			upgraded, err := UpgradeTower(towers[0])
+			if err != nil {
+				t.Fatalf("upgrading tower: %v", err)
+			}
+			multiplier := 1 + tt.bonus
+
+			if merged.Level != 2 {
+				t.Errorf("got level %d, want 2", merged.Level)
+			}
+			if want := int(float64(upgraded.Damage) * multiplier); merged.Damage != want {
+				t.Errorf("got damage %d, want %d", merged.Damage, want)
+			}
+			if want := upgraded.Range * multiplier; merged.Range != want {
+				t.Errorf("got range %v, want %v", merged.Range, want)
+			}
+			if want := upgraded.Speed * multiplier; merged.Speed != want {
+				t.Errorf("got speed %v, want %v", merged.Speed, want)
+			}
+			if merged.Cost != totalCost {
+				t.Errorf("got cost %d, want the %d invested in its parts", merged.Cost, totalCost)
+			}
+			if merged.X != towers[0].X || merged.Y != towers[0].Y {
+				t.Errorf("merged tower stands at (%v, %v), want the first tower's position", merged.X, merged.Y)
+			}
+			if merged.PlayerID != "alice" || merged.ID == towers[0].ID {
+				t.Errorf("merged tower has owner %q and ID %q", merged.PlayerID, merged.ID)
+			}
+		})
+	}
+}
+
+func TestMergeTowersAtMaxTier(t *testing.T) {
+	maxLevel := GetTowerCatalog().MaxLevel(BasicTower)
+
+	a := mergeTower(t, "a", "alice", 150, 150)
+	b := mergeTower(t, "b", "alice", 200, 150)
+	a.Level, b.Level = maxLevel, maxLevel
+
+	_, err := MergeTowers("alice", []models.Tower{a, b})
+	if !errors.Is(err, ErrMergeMaxTier) || !errors.Is(err, ErrMaxLevel) {
+		t.Fatalf("got %v, want %v", err, ErrMergeMaxTier)
+	}
+
+	// One tier below the cap still merges up to it
+	a.Level, b.Level = maxLevel-1, maxLevel-1
+	merged, err := MergeTowers("alice", []models.Tower{a, b})
+	if err != nil {
+		t.Fatalf("merging below the cap: %v", err)
+	}
+	if merged.Level != maxLevel {
+		t.Fatalf("got level %d, want %d", merged.Level, maxLevel)
+	}
+}
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Game phases, in the order a round goes through them
+const (
+	SetupPhase  = "setup"  // Choosing the map, mode, run type and difficulty
+	CardsPhase  = "cards"  // Playing poker hands for gold
+	TowersPhase = "towers" // Building towers before the next wave
+	CombatPhase = "combat" // A wave is being fought
+	EndPhase    = "end"    // The game is over
+)
+
+// Error definitions
+var (
+	ErrInvalidTransition = errors.New("invalid phase transition")
+	ErrWrongPhase        = errors.New("action not allowed in this phase")
+)
+
+// phaseTransitions lists the phases each phase can move to
+var phaseTransitions = map[string][]string{
+	SetupPhase:  {CardsPhase, EndPhase},
+	CardsPhase:  {TowersPhase, EndPhase},
+	TowersPhase: {CombatPhase, EndPhase},
+	CombatPhase: {CardsPhase, EndPhase},
+	EndPhase:    {},
+}
+
+// phaseActions lists the message types allowed in each phase.
+// Message types not listed for any phase are allowed in every phase.
+var phaseActions = map[string][]string{
+	SetupPhase:  {"select_map", "select_run_type", "select_base_mode", "select_difficulty", "deal_cards", "wave_preview", "player_ready", "save_game"},
+	CardsPhase:  {"deal_cards", "hold_card", "discard_card", "hold_hand", "buy_scouting", "wave_preview", "player_ready", "save_game"},
+	TowersPhase: {"place_tower", "upgrade_tower", "merge_towers", "start_wave", "buy_scouting", "wave_preview", "player_ready", "save_game"},
+	CombatPhase: {"place_tower", "upgrade_tower", "merge_towers", "wave_preview"},
+	EndPhase:    {"submit_score"},
+}
+
+// phasedActions holds every message type restricted to some phases
+var phasedActions = make(map[string]bool)
+
+func init() {
+	for _, actions := range phaseActions {
+		for _, action := range actions {
+			phasedActions[action] = true
+		}
+	}
+}
+
+// CanTransition checks if a game can move from one phase to another
+func CanTransition(from, to string) error {
+	for _, next := range phaseTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+}
+
+// CheckAction checks that a message type is allowed in a phase
+func CheckAction(phase, action string) error {
+	if !phasedActions[action] {
+		return nil
+	}
+	for _, allowed := range phaseActions[phase] {
+		if allowed == action {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s during %s", ErrWrongPhase, action, phase)
+}
+
+// PhaseRestricted reports whether a message type is restricted to some phases
+func PhaseRestricted(action string) bool {
+	return phasedActions[action]
+}
+
+// AllowedActions returns the phase-restricted message types allowed in a phase
+func AllowedActions(phase string) []string {
+	return phaseActions[phase]
+}
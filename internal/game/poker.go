@@ -34,6 +34,29 @@ var handRankValues = map[string]int{
 	RoyalFlush:    10,
 }
 
+// handGold is the pay table: gold earned for a final hand by hand rank value
+var handGold = map[int]int{
+	1:  10,  // High Card
+	2:  20,  // Pair
+	3:  30,  // Two Pair
+	4:  50,  // Three of a Kind
+	5:  80,  // Straight
+	6:  100, // Flush
+	7:  150, // Full House
+	8:  200, // Four of a Kind
+	9:  300, // Straight Flush
+	10: 500, // Royal Flush
+}
+
+// GoldForHand returns the gold earned for a final hand based on its rank value, defaulting to 10
+func GoldForHand(handRankValue int) int {
+	gold, ok := handGold[handRankValue]
+	if !ok {
+		gold = 10
+	}
+	return gold
+}
+
 // Hand rank names
 var handRankNames = map[string]string{
 	HighCard:      "High Card",
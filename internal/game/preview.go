@@ -0,0 +1,77 @@
+package game
+
+import (
+	"realtime-game-backend/internal/models"
+)
+
+// Wave preview limits
+const (
+	DefaultPreviewWaves = 3
+	MaxPreviewWaves     = 10
+)
+
+// WavePreviewEntry describes one group or boss in an upcoming wave.
+// Counts and abilities are only filled in for scouted waves.
+type WavePreviewEntry struct {
+	EnemyType string                 `json:"enemyType"`
+	Boss      bool                   `json:"boss"`
+	BossID    string                 `json:"bossId,omitempty"`
+	Count     int                    `json:"count,omitempty"`
+	Abilities *models.EnemyAbilities `json:"abilities,omitempty"`
+}
+
+// WavePreview describes an upcoming wave
+type WavePreview struct {
+	Level   int                `json:"level"`
+	HasBoss bool               `json:"hasBoss"`
+	Scouted bool               `json:"scouted"`
+	Total   int                `json:"total,omitempty"` // Total number of enemies, scouted waves only
+	Entries []WavePreviewEntry `json:"entries"`
+}
+
+// PreviewWave summarizes a wave definition.
+// Without scouting only the enemy types and boss flags are revealed.
+func PreviewWave(def WaveDefinition, scouted bool) WavePreview {
+	preview := WavePreview{
+		Level:   def.Level,
+		HasBoss: len(def.Bosses) > 0,
+		Scouted: scouted,
+	}
+
+	enemyTypes := models.GetEnemyTypes()
+	for _, group := range def.Groups {
+		entry := WavePreviewEntry{EnemyType: group.EnemyType}
+		if scouted {
+			abilities := enemyTypes[group.EnemyType].Abilities
+			if group.Abilities != nil {
+				abilities = combineAbilities(abilities, *group.Abilities)
+			}
+
+			entry.Count = group.Count
+			entry.Abilities = &abilities
+			preview.Total += group.Count
+		}
+		preview.Entries = append(preview.Entries, entry)
+	}
+
+	for _, boss := range def.Bosses {
+		entry := WavePreviewEntry{EnemyType: boss.EnemyType, Boss: true}
+		if boss.BossID != "" {
+			entry.EnemyType = "boss"
+		}
+		if scouted {
+			abilities := enemyTypes[boss.EnemyType].Abilities
+			if bossDef, ok := bossSet.Get(boss.BossID); ok {
+				abilities = bossDef.Abilities
+			}
+
+			entry.BossID = boss.BossID
+			entry.Count = 1
+			entry.Abilities = &abilities
+			preview.Total++
+		}
+		preview.Entries = append(preview.Entries, entry)
+	}
+
+	return preview
+}
@@ -0,0 +1,205 @@
+package game
+
+import (
+	"time"
+
+	"realtime-game-backend/internal/models"
+)
+
+// frameDuration is the length of one client animation frame.
+// Enemy speeds are expressed in pixels per frame, matching the browser client.
+const frameDuration = 16 * time.Millisecond
+
+// Simulation runs the combat for a single wave against a set of towers
+type Simulation struct {
+	Towers  []models.Tower
+	Wave    models.EnemyWave
+	Elapsed int64 // Milliseconds of simulated time since the wave started
+
+	// Stats maps tower IDs to their combat statistics for this wave
+	Stats map[string]*models.TowerStats
+
+	// removed holds the statistics of towers removed mid-wave
+	removed []models.TowerStats
+
+	// Maze is the map the wave is fought on in maze mode, nil when enemies follow fixed lanes
+	Maze *MapDefinition
+
+	// phaseChanges holds boss phase changes not yet drained
+	phaseChanges []BossPhaseChange
+
+	// leaks holds enemies that reached the base and have not been drained yet
+	leaks []models.Enemy
+
+	// bounty is the gold for enemies killed since it was last drained
+	bounty int
+}
+
+// NewSimulation creates a new simulation for a wave
+func NewSimulation(towers []models.Tower, wave models.EnemyWave) *Simulation {
+	sim := &Simulation{
+		Wave:  wave,
+		Stats: make(map[string]*models.TowerStats),
+	}
+
+	for _, tower := range towers {
+		sim.AddTower(tower)
+	}
+
+	return sim
+}
+
+// AddTower adds a tower to a running simulation
+func (s *Simulation) AddTower(tower models.Tower) {
+	// Shots are timed against simulated time, not the wall clock
+	tower.LastShot = 0
+	s.Towers = append(s.Towers, tower)
+
+	s.Stats[tower.ID] = &models.TowerStats{
+		TowerID:   tower.ID,
+		TowerType: tower.Type,
+		PlayerID:  tower.PlayerID,
+		Waves:     1,
+	}
+
+	s.reroute()
+}
+
+// ReplaceTower swaps a tower in a running simulation for an updated copy, such as after an upgrade
+func (s *Simulation) ReplaceTower(tower models.Tower) {
+	for i, existing := range s.Towers {
+		if existing.ID == tower.ID {
+			tower.LastShot = existing.LastShot
+			tower.DisabledUntil = existing.DisabledUntil
+			s.Towers[i] = tower
+			return
+		}
+	}
+}
+
+// RemoveTower removes a tower from a running simulation, keeping its statistics
+func (s *Simulation) RemoveTower(towerID string) {
+	for i, existing := range s.Towers {
+		if existing.ID == towerID {
+			s.Towers = append(s.Towers[:i], s.Towers[i+1:]...)
+			s.removed = append(s.removed, *s.Stats[towerID])
+			s.reroute()
+			return
+		}
+	}
+}
+
+// reroute sends enemies around the towers in maze mode
+func (s *Simulation) reroute() {
+	if s.Maze != nil {
+		s.Wave = RerouteMaze(s.Wave, s.Maze, s.Towers)
+	}
+}
+
+// Tick advances the simulation by the given duration
+func (s *Simulation) Tick(delta time.Duration) {
+	s.Elapsed += delta.Milliseconds()
+
+	// Bring in enemies whose spawn time has arrived
+	s.Wave = SpawnDueEnemies(s.Wave, s.Elapsed)
+
+	// Regenerate health and shields, and let slows wear off
+	s.Wave = RegenerateEnemies(s.Wave, delta)
+	s.Wave = ExpireSlows(s.Wave, s.Elapsed)
+
+	// Move enemies along the path, noting the ones that reach the base
+	wasActive := make([]bool, len(s.Wave.Enemies))
+	for i, enemy := range s.Wave.Enemies {
+		wasActive[i] = enemy.Active
+	}
+	s.Wave = UpdateEnemyPositions(s.Wave, float64(delta)/float64(frameDuration))
+	for i, enemy := range s.Wave.Enemies {
+		if wasActive[i] && !enemy.Active && enemy.Health > 0 {
+			s.leaks = append(s.leaks, enemy)
+		}
+	}
+
+	// Resolve the final stats of every tower, including aura buffs
+	resolved := ResolveTowerStats(s.Towers)
+
+	// Index enemy positions for range queries
+	grid := NewEnemyGrid(s.Wave.Enemies, enemyGridCellSize)
+
+	// Let every tower that is ready fire at its targets
+	for i, tower := range resolved {
+		if tower.Category != DamageCategory || tower.DisabledUntil > s.Elapsed {
+			continue
+		}
+
+		targets := getTowerTargetIndices(tower, s.Wave.Enemies, grid)
+		if len(targets) == 0 {
+			continue
+		}
+
+		// The tower has a target in range for this whole tick
+		stats := s.Stats[tower.ID]
+		stats.Uptime += delta.Milliseconds()
+
+		if !canTowerAttackAt(tower, s.Elapsed) {
+			continue
+		}
+
+		var shot models.TowerStats
+		var gold int
+		s.Wave.Enemies, shot, gold = applyTowerDamage(tower, s.Wave.Enemies, targets, s.Elapsed)
+		s.Towers[i].LastShot = s.Elapsed
+		stats.Add(shot)
+		s.bounty += gold
+	}
+
+	// Move damaged bosses into their next phase
+	s.updateBossPhases()
+}
+
+// DrainBossPhaseChanges returns the boss phase changes since the last call
+func (s *Simulation) DrainBossPhaseChanges() []BossPhaseChange {
+	changes := s.phaseChanges
+	s.phaseChanges = nil
+	return changes
+}
+
+// DrainLeaks returns the enemies that reached the base since the last call
+func (s *Simulation) DrainLeaks() []models.Enemy {
+	leaks := s.leaks
+	s.leaks = nil
+	return leaks
+}
+
+// DrainBounty returns the gold for the enemies killed since the last call
+func (s *Simulation) DrainBounty() int {
+	bounty := s.bounty
+	s.bounty = 0
+	return bounty
+}
+
+// Done checks if the wave has finished
+func (s *Simulation) Done() bool {
+	return IsWaveComplete(s.Wave)
+}
+
+// TowerStats returns the combat statistics of every tower in the simulation
+func (s *Simulation) TowerStats() []models.TowerStats {
+	stats := make([]models.TowerStats, 0, len(s.Towers)+len(s.removed))
+	stats = append(stats, s.removed...)
+	for _, tower := range s.Towers {
+		stats = append(stats, *s.Stats[tower.ID])
+	}
+	return stats
+}
+
+// SummarizeTowerStatsByType totals tower statistics for each tower type
+func SummarizeTowerStatsByType(stats []models.TowerStats) map[string]models.TowerStats {
+	summary := make(map[string]models.TowerStats)
+	for _, towerStats := range stats {
+		total := summary[towerStats.TowerType]
+		total.TowerType = towerStats.TowerType
+		total.Add(towerStats)
+		summary[towerStats.TowerType] = total
+	}
+	return summary
+}
@@ -0,0 +1,75 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"realtime-game-backend/internal/models"
+)
+
+// statsTower returns a damage tower that can hit anything on the map ten times a second
+func statsTower(t *testing.T, id string, damage int, x, y float64) models.Tower {
+	t.Helper()
+
+	tower, err := CreateTower("alice", BasicTower, x, y)
+	if err != nil {
+		t.Fatalf("creating tower: %v", err)
+	}
+	tower.ID, tower.Damage, tower.Range, tower.Speed = id, damage, 1000, 10
+	return tower
+}
+
+func TestSimulationTowerStats(t *testing.T) {
+	wave := CreateEnemyWave(WaveDefinition{Level: 1, Groups: []WaveGroup{{EnemyType: "basic", Count: 1}}}, nil)
+	enemy := &wave.Enemies[0]
+	enemy.Health, enemy.MaxHealth = 25, 25
+	enemy.Active, enemy.Spawned = true, true
+
+	// The weak tower fires first, then the strong one finishes the enemy off with damage to spare
+	weak := statsTower(t, "weak", 10, enemy.X, enemy.Y)
+	strong := statsTower(t, "strong", 30, enemy.X, enemy.Y)
+	idle := statsTower(t, "idle", 10, enemy.X, enemy.Y)
+	idle.Range = 1
+	sim := NewSimulation([]models.Tower{weak, strong, idle}, wave)
+
+	for !sim.Done() && sim.Elapsed < 1000 {
+		sim.Tick(50 * time.Millisecond)
+	}
+	if !sim.Done() {
+		t.Fatal("the enemy survived")
+	}
+
+	// A tower removed mid-wave keeps its stats
+	sim.RemoveTower("idle")
+
+	want := map[string]models.TowerStats{
+		"weak":   {ShotsFired: 1, DamageDealt: 10},
+		"strong": {ShotsFired: 1, DamageDealt: 15, Overkill: 15, Kills: 1},
+		"idle":   {},
+	}
+	stats := sim.TowerStats()
+	if len(stats) != len(want) {
+		t.Fatalf("got stats for %d towers, want %d", len(stats), len(want))
+	}
+	for _, got := range stats {
+		w, ok := want[got.TowerID]
+		if !ok {
+			t.Fatalf("got stats for unknown tower %s", got.TowerID)
+		}
+		if got.ShotsFired != w.ShotsFired || got.DamageDealt != w.DamageDealt || got.Overkill != w.Overkill || got.Kills != w.Kills {
+			t.Errorf("tower %s fired %d shots for %d damage, %d overkill and %d kills, want %d, %d, %d and %d",
+				got.TowerID, got.ShotsFired, got.DamageDealt, got.Overkill, got.Kills, w.ShotsFired, w.DamageDealt, w.Overkill, w.Kills)
+		}
+		if got.TowerType != BasicTower || got.PlayerID != "alice" || got.Waves != 1 {
+			t.Errorf("tower %s stats are for a %s of %q over %d waves", got.TowerID, got.TowerType, got.PlayerID, got.Waves)
+		}
+	}
+
+	// The kill pays out once
+	if bounty := sim.DrainBounty(); bounty != enemy.Gold {
+		t.Fatalf("got a bounty of %d, want %d", bounty, enemy.Gold)
+	}
+	if bounty := sim.DrainBounty(); bounty != 0 {
+		t.Fatalf("got a second bounty of %d", bounty)
+	}
+}
@@ -0,0 +1,156 @@
+package game
+
+import (
+	"math"
+	"sort"
+
+	"realtime-game-backend/internal/models"
+)
+
+// enemyGridCellSize is the side length of a grid cell, about one typical tower range
+const enemyGridCellSize = 100.0
+
+// EnemyGrid is a uniform grid of active enemy positions used for range queries.
+// It is rebuilt every tick, after enemies have moved.
+type EnemyGrid struct {
+	cellSize float64
+	minX     float64
+	minY     float64
+	cols     int
+	rows     int
+
+	// Enemy indices bucketed by cell: the enemies in cell c are
+	// indices[cellStart[c]:cellStart[c+1]], in ascending order
+	cellStart []int
+	indices   []int
+}
+
+// NewEnemyGrid builds a grid of the active enemies
+func NewEnemyGrid(enemies []models.Enemy, cellSize float64) *EnemyGrid {
+	grid := &EnemyGrid{cellSize: cellSize}
+
+	// Size the grid to the bounding box of the active enemies
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	active := 0
+	for _, enemy := range enemies {
+		if !enemy.Active {
+			continue
+		}
+		minX, maxX = math.Min(minX, enemy.X), math.Max(maxX, enemy.X)
+		minY, maxY = math.Min(minY, enemy.Y), math.Max(maxY, enemy.Y)
+		active++
+	}
+
+	if active == 0 {
+		return grid
+	}
+
+	grid.minX, grid.minY = minX, minY
+	grid.cols = int((maxX-minX)/cellSize) + 1
+	grid.rows = int((maxY-minY)/cellSize) + 1
+
+	// Count the enemies in each cell, then bucket them with a prefix sum
+	grid.cellStart = make([]int, grid.cols*grid.rows+1)
+	for _, enemy := range enemies {
+		if enemy.Active {
+			grid.cellStart[grid.cellIndex(enemy.X, enemy.Y)+1]++
+		}
+	}
+	for c := 1; c < len(grid.cellStart); c++ {
+		grid.cellStart[c] += grid.cellStart[c-1]
+	}
+
+	grid.indices = make([]int, active)
+	next := make([]int, grid.cols*grid.rows)
+	copy(next, grid.cellStart)
+	for i, enemy := range enemies {
+		if enemy.Active {
+			c := grid.cellIndex(enemy.X, enemy.Y)
+			grid.indices[next[c]] = i
+			next[c]++
+		}
+	}
+
+	return grid
+}
+
+// cellIndex returns the index of the cell containing a position inside the grid
+func (g *EnemyGrid) cellIndex(x, y float64) int {
+	col := int((x - g.minX) / g.cellSize)
+	row := int((y - g.minY) / g.cellSize)
+	return row*g.cols + col
+}
+
+// cellRange returns the clamped column and row bounds of the cells overlapping a circle
+func (g *EnemyGrid) cellRange(x, y, radius float64) (minCol, maxCol, minRow, maxRow int, ok bool) {
+	if g.cols == 0 {
+		return 0, 0, 0, 0, false
+	}
+
+	minCol = int(math.Floor((x - radius - g.minX) / g.cellSize))
+	maxCol = int(math.Floor((x + radius - g.minX) / g.cellSize))
+	minRow = int(math.Floor((y - radius - g.minY) / g.cellSize))
+	maxRow = int(math.Floor((y + radius - g.minY) / g.cellSize))
+
+	if maxCol < 0 || maxRow < 0 || minCol >= g.cols || minRow >= g.rows {
+		return 0, 0, 0, 0, false
+	}
+
+	minCol = max(minCol, 0)
+	minRow = max(minRow, 0)
+	maxCol = min(maxCol, g.cols-1)
+	maxRow = min(maxRow, g.rows-1)
+	return minCol, maxCol, minRow, maxRow, true
+}
+
+// FirstInRange returns the lowest enemy index for which inRange is true among the enemies near a circle, or -1
+func (g *EnemyGrid) FirstInRange(x, y, radius float64, inRange func(i int) bool) int {
+	minCol, maxCol, minRow, maxRow, ok := g.cellRange(x, y, radius)
+	if !ok {
+		return -1
+	}
+
+	first := -1
+	for row := minRow; row <= maxRow; row++ {
+		for col := minCol; col <= maxCol; col++ {
+			c := row*g.cols + col
+			for _, i := range g.indices[g.cellStart[c]:g.cellStart[c+1]] {
+				// Cells are sorted, so later enemies in this cell can't beat the current best
+				if first != -1 && i >= first {
+					break
+				}
+				if inRange(i) {
+					first = i
+					break
+				}
+			}
+		}
+	}
+
+	return first
+}
+
+// AllInRange returns every enemy index for which inRange is true among the enemies near a circle, in ascending order
+func (g *EnemyGrid) AllInRange(x, y, radius float64, inRange func(i int) bool) []int {
+	minCol, maxCol, minRow, maxRow, ok := g.cellRange(x, y, radius)
+	if !ok {
+		return nil
+	}
+
+	var matches []int
+	for row := minRow; row <= maxRow; row++ {
+		for col := minCol; col <= maxCol; col++ {
+			c := row*g.cols + col
+			for _, i := range g.indices[g.cellStart[c]:g.cellStart[c+1]] {
+				if inRange(i) {
+					matches = append(matches, i)
+				}
+			}
+		}
+	}
+
+	// Keep the enemy order so results match a linear scan
+	sort.Ints(matches)
+	return matches
+}
@@ -0,0 +1,122 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"realtime-game-backend/internal/models"
+)
+
+// benchmarkArena is the side length of the square the benchmark scatters enemies and towers over
+const benchmarkArena = 2000.0
+
+// benchmarkEnemies scatters active enemies over the arena
+func benchmarkEnemies(r *rand.Rand, count int) []models.Enemy {
+	enemies := make([]models.Enemy, count)
+	for i := range enemies {
+		enemies[i] = models.Enemy{
+			ID:        fmt.Sprintf("enemy-%d", i),
+			Type:      "basic",
+			Health:    1 << 30, // Enemies never die so every tick does the same work
+			MaxHealth: 1 << 30,
+			Speed:     1.0,
+			X:         r.Float64() * benchmarkArena,
+			Y:         r.Float64() * benchmarkArena,
+			Active:    true,
+			Spawned:   true,
+		}
+	}
+	return enemies
+}
+
+// benchmarkTowers scatters damage towers over the arena, cycling through the built-in types
+func benchmarkTowers(r *rand.Rand, count int) []models.Tower {
+	towerTypes := []string{BasicTower, SplashTower, SniperTower, SlowTower}
+
+	towers := make([]models.Tower, count)
+	for i := range towers {
+		tower, err := CreateTower("bench", towerTypes[i%len(towerTypes)], r.Float64()*benchmarkArena, r.Float64()*benchmarkArena)
+		if err != nil {
+			panic(err)
+		}
+		tower.ID = fmt.Sprintf("tower-%d", i)
+		towers[i] = tower
+	}
+	return towers
+}
+
+// TestEnemyGridMatchesLinearScan checks that grid queries pick the same targets as a linear scan
+func TestEnemyGridMatchesLinearScan(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	enemies := benchmarkEnemies(r, 2000)
+	towers := benchmarkTowers(r, 200)
+
+	// Deactivate some enemies so the grid has to skip them
+	for i := 0; i < len(enemies); i += 7 {
+		enemies[i].Active = false
+	}
+
+	grid := NewEnemyGrid(enemies, enemyGridCellSize)
+	for _, tower := range towers {
+		linear := getTowerTargetIndices(tower, enemies, nil)
+		indexed := getTowerTargetIndices(tower, enemies, grid)
+
+		if fmt.Sprint(linear) != fmt.Sprint(indexed) {
+			t.Fatalf("tower %s (%s): linear targets %v, grid targets %v", tower.ID, tower.Type, linear, indexed)
+		}
+	}
+}
+
+// BenchmarkTowerTargeting compares a linear scan against the enemy grid for every tower
+func BenchmarkTowerTargeting(b *testing.B) {
+	for _, enemyCount := range []int{100, 1000, 5000} {
+		for _, towerCount := range []int{10, 100, 500} {
+			r := rand.New(rand.NewSource(1))
+			enemies := benchmarkEnemies(r, enemyCount)
+			towers := benchmarkTowers(r, towerCount)
+
+			name := fmt.Sprintf("enemies=%d/towers=%d", enemyCount, towerCount)
+
+			b.Run(name+"/linear", func(b *testing.B) {
+				for n := 0; n < b.N; n++ {
+					for _, tower := range towers {
+						getTowerTargetIndices(tower, enemies, nil)
+					}
+				}
+			})
+
+			b.Run(name+"/grid", func(b *testing.B) {
+				for n := 0; n < b.N; n++ {
+					grid := NewEnemyGrid(enemies, enemyGridCellSize)
+					for _, tower := range towers {
+						getTowerTargetIndices(tower, enemies, grid)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkSimulationTick measures a full simulation tick, including movement, aura resolution and the grid rebuild
+func BenchmarkSimulationTick(b *testing.B) {
+	for _, enemyCount := range []int{100, 1000, 5000} {
+		for _, towerCount := range []int{10, 100, 500} {
+			b.Run(fmt.Sprintf("enemies=%d/towers=%d", enemyCount, towerCount), func(b *testing.B) {
+				r := rand.New(rand.NewSource(1))
+				wave := models.EnemyWave{
+					ID:      "bench",
+					Enemies: benchmarkEnemies(r, enemyCount),
+					Path:    []models.Point{{X: 0, Y: 0}, {X: benchmarkArena, Y: benchmarkArena}},
+				}
+				sim := NewSimulation(benchmarkTowers(r, towerCount), wave)
+
+				b.ResetTimer()
+				for n := 0; n < b.N; n++ {
+					sim.Tick(50 * time.Millisecond)
+				}
+			})
+		}
+	}
+}
@@ -1,87 +1,119 @@
 package game
 
 import (
+	"fmt"
 	"math"
 	"time"
 
 	"realtime-game-backend/internal/models"
 )
 
-// Tower types
+// Built-in tower types. Additional types can be defined in the tower catalog.
 const (
 	BasicTower  = "basic"
 	SplashTower = "splash"
 	SniperTower = "sniper"
 	SlowTower   = "slow"
+
+	// Support towers buff damage towers within their aura radius
+	RangeAuraTower  = "range_aura"
+	DamageAuraTower = "damage_aura"
+	SpeedAuraTower  = "speed_aura"
+
+	// Economy towers generate gold instead of attacking
+	BankTower = "bank"
 )
 
-// Tower costs
-var towerCosts = map[string]int{
-	BasicTower:  50,
-	SplashTower: 100,
-	SniperTower: 150,
-	SlowTower:   75,
-}
+// Tower categories
+const (
+	DamageCategory  = "damage"
+	SupportCategory = "support"
+	EconomyCategory = "economy"
+)
 
-// Tower ranges
-var towerRanges = map[string]float64{
-	BasicTower:  100,
-	SplashTower: 75,
-	SniperTower: 200,
-	SlowTower:   100,
-}
+// CreateTower creates a new tower from its catalog definition
+func CreateTower(playerID, towerType string, x, y float64) (models.Tower, error) {
+	def, err := GetTowerDefinition(towerType)
+	if err != nil {
+		return models.Tower{}, err
+	}
 
-// Tower damages
-var towerDamages = map[string]int{
-	BasicTower:  10,
-	SplashTower: 5,  // Splash damage to multiple enemies
-	SniperTower: 30, // High damage to single enemy
-	SlowTower:   5,  // Low damage but slows enemies
-}
+	tower := models.Tower{
+		ID:           GenerateID(),
+		PlayerID:     playerID,
+		Type:         def.Type,
+		Category:     def.Category,
+		Level:        1,
+		X:            x,
+		Y:            y,
+		Range:        def.Range,
+		Damage:       def.Damage,
+		DamageType:   def.DamageType,
+		Speed:        def.Speed,
+		Cost:         def.Cost,
+		LastShot:     0,
+		Splash:       def.Splash,
+		SlowFactor:   def.SlowFactor,
+		SlowDuration: def.SlowDuration,
+		GroundOnly:   def.GroundOnly,
+		Detection:    def.Detection,
+		GoldPerWave:  def.GoldPerWave,
+	}
+
+	if def.Aura != nil {
+		tower.AuraRadius = def.Aura.Radius
+		tower.AuraRangeBonus = def.Aura.RangeBonus
+		tower.AuraDamageBonus = def.Aura.DamageBonus
+		tower.AuraSpeedBonus = def.Aura.SpeedBonus
+		tower.AuraDetection = def.Aura.Detection
+	}
 
-// Tower attack speeds (attacks per second)
-var towerSpeeds = map[string]float64{
-	BasicTower:  1.0,
-	SplashTower: 0.5,
-	SniperTower: 0.5,
-	SlowTower:   1.5,
+	return tower, nil
 }
 
-// CreateTower creates a new tower
-func CreateTower(playerID, towerType string, x, y float64) models.Tower {
-	return models.Tower{
-		ID:       GenerateID(),
-		PlayerID: playerID,
-		Type:     towerType,
-		Level:    1,
-		X:        x,
-		Y:        y,
-		Range:    towerRanges[towerType],
-		Damage:   towerDamages[towerType],
-		Speed:    towerSpeeds[towerType],
-		Cost:     towerCosts[towerType],
-		LastShot: 0,
+// UpgradeTower upgrades a tower to the next level using the catalog's upgrade rules
+func UpgradeTower(tower models.Tower) (models.Tower, error) {
+	if tower.Level >= towerCatalog.MaxLevel(tower.Type) {
+		return models.Tower{}, fmt.Errorf("%w: %s level %d", ErrMaxLevel, tower.Type, tower.Level)
 	}
-}
 
-// UpgradeTower upgrades a tower to the next level
-func UpgradeTower(tower models.Tower) models.Tower {
+	u := towerCatalog.Upgrades
 	tower.Level++
-	tower.Range *= 1.2
-	tower.Damage = int(float64(tower.Damage) * 1.5)
-	tower.Speed *= 1.2
-	tower.Cost = int(float64(tower.Cost) * 1.5)
-	return tower
+	tower.Range *= u.StatMultiplier
+	tower.Damage = int(float64(tower.Damage) * u.DamageMultiplier)
+	tower.Speed *= u.StatMultiplier
+	tower.Cost = int(float64(tower.Cost) * u.CostMultiplier)
+
+	// Support and economy towers improve their aura and income instead
+	tower.AuraRadius *= u.StatMultiplier
+	tower.AuraRangeBonus *= u.StatMultiplier
+	tower.AuraDamageBonus *= u.StatMultiplier
+	tower.AuraSpeedBonus *= u.StatMultiplier
+	tower.GoldPerWave = int(float64(tower.GoldPerWave) * u.IncomeMultiplier)
+	return tower, nil
 }
 
 // GetTowerUpgradeCost returns the cost to upgrade a tower
 func GetTowerUpgradeCost(tower models.Tower) int {
-	return int(float64(towerCosts[tower.Type]) * math.Pow(1.5, float64(tower.Level)))
+	baseCost := tower.Cost
+	if def, ok := towerCatalog.Get(tower.Type); ok {
+		baseCost = def.Cost
+	}
+	return int(float64(baseCost) * math.Pow(towerCatalog.Upgrades.CostMultiplier, float64(tower.Level)))
 }
 
 // CanTowerAttack checks if a tower can attack based on its attack speed
 func CanTowerAttack(tower models.Tower) bool {
-	now := time.Now().UnixNano() / int64(time.Millisecond)
+	return canTowerAttackAt(tower, time.Now().UnixNano()/int64(time.Millisecond))
+}
+
+// canTowerAttackAt checks if a tower can attack at the given time in milliseconds
+func canTowerAttackAt(tower models.Tower, now int64) bool {
+	// Only damage towers attack
+	if tower.Category != DamageCategory || tower.Speed <= 0 {
+		return false
+	}
+
 	attackInterval := int64(1000 / tower.Speed) // Convert attacks per second to milliseconds per attack
 	return now-tower.LastShot >= attackInterval
 }
@@ -94,69 +126,131 @@ func UpdateTowerLastShot(tower *models.Tower) {
 // GetTowerTargets gets the targets for a tower
 func GetTowerTargets(tower models.Tower, enemies []models.Enemy) []models.Enemy {
 	var targets []models.Enemy
+	for _, i := range getTowerTargetIndices(tower, enemies, nil) {
+		targets = append(targets, enemies[i])
+	}
+	return targets
+}
 
-	for _, enemy := range enemies {
-		if !enemy.Active {
-			continue
+// getTowerTargetIndices gets the indices of the enemies a tower targets.
+// If grid is nil every enemy is checked, otherwise only enemies in nearby grid cells.
+func getTowerTargetIndices(tower models.Tower, enemies []models.Enemy, grid *EnemyGrid) []int {
+	if grid != nil {
+		inRange := func(i int) bool {
+			return isTargetInRange(tower, enemies[i])
 		}
 
-		// Calculate distance between tower and enemy
-		distance := math.Sqrt(math.Pow(tower.X-enemy.X, 2) + math.Pow(tower.Y-enemy.Y, 2))
+		// For non-splash towers, only target the first enemy in range
+		if !tower.Splash {
+			if first := grid.FirstInRange(tower.X, tower.Y, tower.Range, inRange); first >= 0 {
+				return []int{first}
+			}
+			return nil
+		}
 
-		// Check if enemy is in range
-		if distance <= tower.Range {
-			targets = append(targets, enemy)
+		return grid.AllInRange(tower.X, tower.Y, tower.Range, inRange)
+	}
 
-			// For non-splash towers, only target the first enemy in range
-			if tower.Type != SplashTower {
-				break
-			}
+	var targets []int
+	for i, enemy := range enemies {
+		if !isTargetInRange(tower, enemy) {
+			continue
+		}
+
+		targets = append(targets, i)
+
+		// For non-splash towers, only target the first enemy in range
+		if !tower.Splash {
+			break
 		}
 	}
 
 	return targets
 }
 
+// isTargetInRange checks if an enemy is active and within a tower's range
+func isTargetInRange(tower models.Tower, enemy models.Enemy) bool {
+	if !enemy.Active {
+		return false
+	}
+
+	// Flying enemies can't be hit by ground-only towers
+	if enemy.Abilities.Flying && tower.GroundOnly {
+		return false
+	}
+
+	// Invisible enemies need detection
+	if enemy.Abilities.Invisible && !tower.Detection {
+		return false
+	}
+
+	// Bosses can become immune to a tower type
+	if len(enemy.ImmuneTo) > 0 && isImmune(enemy, tower.Type) {
+		return false
+	}
+
+	// Compare squared distances to avoid a square root
+	dx := tower.X - enemy.X
+	dy := tower.Y - enemy.Y
+	return dx*dx+dy*dy <= tower.Range*tower.Range
+}
+
 // ApplyTowerDamage applies damage from a tower to enemies
 func ApplyTowerDamage(tower models.Tower, enemies []models.Enemy) []models.Enemy {
-	targets := GetTowerTargets(tower, enemies)
+	enemies, _ = ApplyTowerDamageWithStats(tower, enemies)
+	return enemies
+}
+
+// ApplyTowerDamageWithStats applies damage from a tower to enemies and returns the combat statistics of the shot
+func ApplyTowerDamageWithStats(tower models.Tower, enemies []models.Enemy) ([]models.Enemy, models.TowerStats) {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	enemies, stats, _ := applyTowerDamage(tower, enemies, getTowerTargetIndices(tower, enemies, nil), now)
+	return enemies, stats
+}
+
+// applyTowerDamage applies damage from a tower to the enemies at the target indices at a time in milliseconds.
+// It returns the gold the enemies it killed are worth along with the shot's statistics.
+func applyTowerDamage(tower models.Tower, enemies []models.Enemy, targets []int, now int64) ([]models.Enemy, models.TowerStats, int) {
+	stats := models.TowerStats{TowerID: tower.ID, TowerType: tower.Type}
+	gold := 0
 	if len(targets) == 0 {
-		return enemies
+		return enemies, stats, gold
 	}
 
 	// Update the last shot timestamp
 	UpdateTowerLastShot(&tower)
+	stats.ShotsFired++
 
 	// Apply damage to targets
-	for i, target := range targets {
-		for j, enemy := range enemies {
-			if enemy.ID == target.ID {
-				enemies[j].Health -= tower.Damage
-
-				// Apply slow effect for slow towers
-				if tower.Type == SlowTower {
-					enemies[j].Speed *= 0.7 // Reduce speed by 30%
-				}
-
-				// Check if enemy is dead
-				if enemies[j].Health <= 0 {
-					enemies[j].Active = false
-				}
-
-				// For non-splash towers, only damage the first target
-				if tower.Type != SplashTower {
-					break
-				}
-			}
+	for _, j := range targets {
+		dealt := DamageEnemy(&enemies[j], tower.Damage, tower.DamageType)
+
+		// Record the damage that actually landed
+		if enemies[j].Health <= 0 {
+			overkill := -enemies[j].Health
+			stats.DamageDealt += dealt - overkill
+			stats.Overkill += overkill
+			stats.Kills++
+		} else {
+			stats.DamageDealt += dealt
 		}
 
-		// For non-splash towers, only damage the first target
-		if tower.Type != SplashTower && i == 0 {
-			break
+		// Apply slow effect for slow towers
+		if tower.SlowFactor > 0 {
+			SlowEnemy(&enemies[j], tower.SlowFactor, now+tower.SlowDuration)
+		}
+
+		// Check if enemy is dead
+		if enemies[j].Health <= 0 {
+			enemies[j].Active = false
+			gold += enemies[j].Gold
+
+			// Splitting enemies release their children where they died
+			enemies = append(enemies, SplitEnemy(enemies[j])...)
 		}
 	}
 
-	return enemies
+	return enemies, stats, gold
 }
 
 // GenerateID generates a unique ID
@@ -0,0 +1,115 @@
+package game
+
+import (
+	"testing"
+
+	"realtime-game-backend/internal/models"
+)
+
+// catalogTower creates a tower from the catalog with a fixed ID
+func catalogTower(t *testing.T, id, playerID, towerType string, x, y float64) models.Tower {
+	t.Helper()
+
+	tower, err := CreateTower(playerID, towerType, x, y)
+	if err != nil {
+		t.Fatalf("creating %s tower: %v", towerType, err)
+	}
+	tower.ID = id
+	return tower
+}
+
+func TestResolveTowerStatsStacksAurasAdditively(t *testing.T) {
+	basic := catalogTower(t, "basic", "alice", BasicTower, 500, 500)
+	damage1 := catalogTower(t, "damage-1", "alice", DamageAuraTower, 550, 500)
+	damage2 := catalogTower(t, "damage-2", "bob", DamageAuraTower, 500, 550)
+	speed := catalogTower(t, "speed", "alice", SpeedAuraTower, 450, 500)
+	rangeAura := catalogTower(t, "range", "alice", RangeAuraTower, 500, 400)
+	farAway := catalogTower(t, "far", "alice", DamageAuraTower, 800, 800)
+	towers := []models.Tower{basic, damage1, damage2, speed, rangeAura, farAway}
+
+	resolved := ResolveTowerStats(towers)
+
+	// Both damage auras in range add up, including another player's, and the distant one doesn't count
+	damageBonus := damage1.AuraDamageBonus + damage2.AuraDamageBonus
+	if want := int(float64(basic.Damage) * (1 + damageBonus)); resolved[0].Damage != want {
+		t.Errorf("got damage %d, want %d", resolved[0].Damage, want)
+	}
+	if want := basic.Speed * (1 + speed.AuraSpeedBonus); resolved[0].Speed != want {
+		t.Errorf("got speed %v, want %v", resolved[0].Speed, want)
+	}
+	if want := basic.Range * (1 + rangeAura.AuraRangeBonus); resolved[0].Range != want {
+		t.Errorf("got range %v, want %v", resolved[0].Range, want)
+	}
+
+	// Support towers aren't buffed, and the towers passed in are left alone
+	for i := 1; i < len(towers); i++ {
+		if resolved[i] != towers[i] {
+			t.Errorf("support tower %s changed to %+v", towers[i].ID, resolved[i])
+		}
+	}
+	if towers[0] != basic {
+		t.Error("resolving changed the tower passed in")
+	}
+}
+
+func TestResolveTowerStatsAuraEdge(t *testing.T) {
+	aura := catalogTower(t, "aura", "alice", DamageAuraTower, 0, 0)
+	edge := catalogTower(t, "edge", "alice", BasicTower, aura.AuraRadius, 0)
+	outside := catalogTower(t, "outside", "alice", BasicTower, 0, aura.AuraRadius+1)
+
+	resolved := ResolveTowerStats([]models.Tower{aura, edge, outside})
+	if resolved[1].Damage <= edge.Damage {
+		t.Error("tower on the edge of the aura wasn't buffed")
+	}
+	if resolved[2].Damage != outside.Damage {
+		t.Error("tower outside the aura was buffed")
+	}
+}
+
+func TestResolveTowerStatsGrantsDetection(t *testing.T) {
+	radar := catalogTower(t, "radar", "alice", "radar", 0, 0)
+	covered := catalogTower(t, "covered", "alice", BasicTower, radar.AuraRadius/2, 0)
+	uncovered := catalogTower(t, "uncovered", "alice", BasicTower, radar.AuraRadius*2, 0)
+	sniper := catalogTower(t, "sniper", "alice", SniperTower, radar.AuraRadius*2, 0)
+	if covered.Detection || uncovered.Detection || !sniper.Detection {
+		t.Fatal("catalog detection changed, fix the test towers")
+	}
+
+	resolved := ResolveTowerStats([]models.Tower{radar, covered, uncovered, sniper})
+	if !resolved[1].Detection {
+		t.Error("tower in the radar's aura can't see invisible enemies")
+	}
+	if resolved[2].Detection {
+		t.Error("tower outside the radar's aura can see invisible enemies")
+	}
+	if !resolved[3].Detection {
+		t.Error("sniper lost its own detection")
+	}
+	if resolved[1].Damage != covered.Damage || resolved[1].Range != covered.Range {
+		t.Error("radar buffed more than detection")
+	}
+}
+
+func TestCalculateBankGold(t *testing.T) {
+	bank := catalogTower(t, "bank", "alice", BankTower, 0, 0)
+	upgraded, err := UpgradeTower(catalogTower(t, "upgraded", "alice", BankTower, 100, 0))
+	if err != nil {
+		t.Fatalf("upgrading bank: %v", err)
+	}
+	towers := []models.Tower{
+		bank,
+		upgraded,
+		catalogTower(t, "bob-bank", "bob", BankTower, 200, 0),
+		catalogTower(t, "basic", "carol", BasicTower, 300, 0),
+	}
+
+	gold := CalculateBankGold(towers)
+
+	want := map[string]int{
+		"alice": bank.GoldPerWave + int(float64(bank.GoldPerWave)*GetTowerCatalog().Upgrades.IncomeMultiplier),
+		"bob":   bank.GoldPerWave,
+	}
+	if len(gold) != len(want) || gold["alice"] != want["alice"] || gold["bob"] != want["bob"] {
+		t.Fatalf("got bank gold %v, want %v", gold, want)
+	}
+}
@@ -0,0 +1,243 @@
+package game
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"realtime-game-backend/internal/models"
+)
+
+// defaultWaveSet is the wave set shipped with the server
+//
+//go:embed data/waves.json
+var defaultWaveSet []byte
+
+// waveSet is the wave set used to build every wave
+var waveSet *WaveSet
+
+// The default bosses and waves are loaded together so the waves' bosses can be checked
+func init() {
+	bosses, err := ParseBossSet(defaultBossSet)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default boss set: %v", err))
+	}
+	waves, err := ParseWaveSet(defaultWaveSet)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default wave set: %v", err))
+	}
+	if err := SetWaveData(bosses, waves); err != nil {
+		panic(fmt.Sprintf("invalid default wave set: %v", err))
+	}
+}
+
+// WaveScaling describes how waves get harder with each level
+type WaveScaling struct {
+	HealthPerLevel float64 `json:"healthPerLevel"` // Fractional health increase per level
+	SpeedPerLevel  float64 `json:"speedPerLevel"`  // Fractional speed increase per level
+	GoldPerLevel   float64 `json:"goldPerLevel"`   // Fractional gold increase per level
+	CountPerLevel  float64 `json:"countPerLevel"`  // Fractional enemy count increase per level past the last defined wave
+}
+
+// WaveGroup describes a group of identical enemies in a wave
+type WaveGroup struct {
+	EnemyType        string  `json:"enemyType"`
+	Count            int     `json:"count"`
+	Interval         int64   `json:"interval"`                   // Milliseconds between spawns in the group
+	Delay            int64   `json:"delay,omitempty"`            // Milliseconds after the wave starts before the group spawns
+	HealthMultiplier float64 `json:"healthMultiplier,omitempty"` // Defaults to 1
+	SpeedMultiplier  float64 `json:"speedMultiplier,omitempty"`  // Defaults to 1
+	GoldMultiplier   float64 `json:"goldMultiplier,omitempty"`   // Defaults to 1
+
+	// Abilities added on top of the enemy type's own
+	Abilities *models.EnemyAbilities `json:"abilities,omitempty"`
+}
+
+// WaveBoss describes a boss entry in a wave.
+// Scripted bosses are referenced by BossID, plain tougher enemies by EnemyType.
+type WaveBoss struct {
+	BossID           string  `json:"bossId,omitempty"`
+	EnemyType        string  `json:"enemyType,omitempty"`
+	Delay            int64   `json:"delay,omitempty"` // Milliseconds after the wave starts before the boss spawns
+	HealthMultiplier float64 `json:"healthMultiplier,omitempty"`
+	SpeedMultiplier  float64 `json:"speedMultiplier,omitempty"`
+	GoldMultiplier   float64 `json:"goldMultiplier,omitempty"`
+}
+
+// WaveDefinition describes the enemies of one wave level
+type WaveDefinition struct {
+	Level  int         `json:"level"`
+	Groups []WaveGroup `json:"groups"`
+	Bosses []WaveBoss  `json:"bosses,omitempty"`
+}
+
+// WaveSet describes a whole campaign of waves
+type WaveSet struct {
+	Scaling WaveScaling      `json:"scaling"`
+	Waves   []WaveDefinition `json:"waves"`
+}
+
+// ParseWaveSet parses and validates a JSON wave set
+func ParseWaveSet(data []byte) (*WaveSet, error) {
+	var set WaveSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	if err := set.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &set, nil
+}
+
+// LoadWaveSet loads and validates a wave set from a JSON file
+func LoadWaveSet(path string) (*WaveSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := ParseWaveSet(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return set, nil
+}
+
+// Validate checks that the waves are defined in order and only use known enemy types.
+// Scripted bosses are checked separately by ValidateBosses.
+func (s *WaveSet) Validate() error {
+	if len(s.Waves) == 0 {
+		return errors.New("wave set is empty")
+	}
+
+	enemyTypes := models.GetEnemyTypes()
+	for i, wave := range s.Waves {
+		if wave.Level != i+1 {
+			return fmt.Errorf("wave %d: levels must start at 1 and have no gaps", i+1)
+		}
+		if len(wave.Groups) == 0 && len(wave.Bosses) == 0 {
+			return fmt.Errorf("wave %d: needs at least one group or boss", wave.Level)
+		}
+
+		for _, group := range wave.Groups {
+			if _, ok := enemyTypes[group.EnemyType]; !ok {
+				return fmt.Errorf("wave %d: unknown enemy type %q", wave.Level, group.EnemyType)
+			}
+			if group.Count <= 0 {
+				return fmt.Errorf("wave %d: %s group needs a positive count", wave.Level, group.EnemyType)
+			}
+			if group.Interval < 0 || group.Delay < 0 {
+				return fmt.Errorf("wave %d: %s group can't have a negative interval or delay", wave.Level, group.EnemyType)
+			}
+			if group.HealthMultiplier < 0 || group.SpeedMultiplier < 0 || group.GoldMultiplier < 0 {
+				return fmt.Errorf("wave %d: %s group can't have a negative multiplier", wave.Level, group.EnemyType)
+			}
+			if group.Abilities != nil && group.Abilities.SplitCount > 0 {
+				if _, ok := enemyTypes[group.Abilities.SplitType]; !ok {
+					return fmt.Errorf("wave %d: %s group splits into unknown enemy type %q", wave.Level, group.EnemyType, group.Abilities.SplitType)
+				}
+			}
+		}
+
+		for _, boss := range wave.Bosses {
+			if boss.BossID == "" {
+				if _, ok := enemyTypes[boss.EnemyType]; !ok {
+					return fmt.Errorf("wave %d: unknown boss enemy type %q", wave.Level, boss.EnemyType)
+				}
+			}
+			if boss.Delay < 0 {
+				return fmt.Errorf("wave %d: boss can't have a negative delay", wave.Level)
+			}
+		}
+	}
+
+	// Levels past the end repeat the last wave's groups
+	if last := s.Waves[len(s.Waves)-1]; len(last.Groups) == 0 {
+		return fmt.Errorf("wave %d: the last wave needs at least one group", last.Level)
+	}
+
+	return nil
+}
+
+// ValidateBosses checks that every scripted boss in the waves is defined in a boss set
+func (s *WaveSet) ValidateBosses(bosses *BossSet) error {
+	for _, wave := range s.Waves {
+		for _, boss := range wave.Bosses {
+			if boss.BossID == "" {
+				continue
+			}
+			if _, ok := bosses.Get(boss.BossID); !ok {
+				return fmt.Errorf("wave %d: %w %q", wave.Level, ErrUnknownBoss, boss.BossID)
+			}
+		}
+	}
+	return nil
+}
+
+// Definition returns the definition for a wave level.
+// Levels past the last defined wave repeat its groups with more enemies, but not its bosses.
+func (s *WaveSet) Definition(level int) WaveDefinition {
+	if level < 1 {
+		level = 1
+	}
+	if level <= len(s.Waves) {
+		return s.Waves[level-1]
+	}
+
+	last := s.Waves[len(s.Waves)-1]
+	extraLevels := float64(level - last.Level)
+
+	def := WaveDefinition{
+		Level:  level,
+		Groups: make([]WaveGroup, len(last.Groups)),
+	}
+	for i, group := range last.Groups {
+		group.Count = int(math.Ceil(float64(group.Count) * (1 + extraLevels*s.Scaling.CountPerLevel)))
+		def.Groups[i] = group
+	}
+
+	return def
+}
+
+// FinalLevel returns the level of the last defined wave, which ends a campaign run
+func (s *WaveSet) FinalLevel() int {
+	return len(s.Waves)
+}
+
+// SetWaveData replaces the boss set and wave set used to build every wave, after checking
+// that the waves only use bosses from the boss set. A nil set keeps the one in use.
+func SetWaveData(bosses *BossSet, waves *WaveSet) error {
+	if bosses == nil {
+		bosses = bossSet
+	}
+	if waves == nil {
+		waves = waveSet
+	}
+
+	if err := waves.ValidateBosses(bosses); err != nil {
+		return err
+	}
+
+	bossSet = bosses
+	waveSet = waves
+	return nil
+}
+
+// GetWaveSet returns the wave set used to build every wave
+func GetWaveSet() *WaveSet {
+	return waveSet
+}
+
+// orOne returns a multiplier, treating an unset multiplier as 1
+func orOne(multiplier float64) float64 {
+	if multiplier == 0 {
+		return 1
+	}
+	return multiplier
+}
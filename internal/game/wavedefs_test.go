@@ -0,0 +1,112 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+// testWaveSet returns a two-wave set whose last wave has a boss
+func testWaveSet() *WaveSet {
+	return &WaveSet{
+		Scaling: WaveScaling{CountPerLevel: 0.5},
+		Waves: []WaveDefinition{
+			{Level: 1, Groups: []WaveGroup{{EnemyType: "basic", Count: 4, Interval: 500}}},
+			{
+				Level:  2,
+				Groups: []WaveGroup{{EnemyType: "fast", Count: 2, Interval: 500}},
+				Bosses: []WaveBoss{{EnemyType: "tank"}},
+			},
+		},
+	}
+}
+
+func TestDefinitionWithinSet(t *testing.T) {
+	set := testWaveSet()
+
+	for level := 0; level <= 2; level++ {
+		want := max(level, 1)
+		if def := set.Definition(level); def.Level != want {
+			t.Errorf("level %d: got wave %d, want %d", level, def.Level, want)
+		}
+	}
+	if def := set.Definition(2); len(def.Bosses) != 1 {
+		t.Fatalf("level 2: got %d bosses, want 1", len(def.Bosses))
+	}
+}
+
+func TestDefinitionPastEndOfSet(t *testing.T) {
+	set := testWaveSet()
+
+	tests := []struct {
+		level int
+		count int
+	}{
+		{3, 3},
+		{4, 4},
+		{6, 6},
+	}
+
+	for _, tt := range tests {
+		def := set.Definition(tt.level)
+		if def.Level != tt.level {
+			t.Errorf("level %d: got level %d", tt.level, def.Level)
+		}
+		if len(def.Bosses) != 0 {
+			t.Errorf("level %d: repeated %d bosses from the last wave", tt.level, len(def.Bosses))
+		}
+		if len(def.Groups) != 1 || def.Groups[0].EnemyType != "fast" || def.Groups[0].Count != tt.count {
+			t.Errorf("level %d: got groups %+v, want %d fast enemies", tt.level, def.Groups, tt.count)
+		}
+	}
+
+	// Extrapolating must not change the defined waves
+	if count := set.Waves[1].Groups[0].Count; count != 2 {
+		t.Fatalf("last wave's group count changed to %d", count)
+	}
+}
+
+func TestValidateRequiresGroupsInLastWave(t *testing.T) {
+	set := testWaveSet()
+	set.Waves[1].Groups = nil
+
+	if err := set.Validate(); err == nil {
+		t.Fatal("accepted a set whose last wave only has bosses")
+	}
+}
+
+func TestValidateBosses(t *testing.T) {
+	bosses := GetBossSet()
+	if len(bosses.Bosses) == 0 {
+		t.Skip("no bosses defined")
+	}
+
+	set := testWaveSet()
+	set.Waves[1].Bosses = []WaveBoss{{BossID: bosses.Bosses[0].ID}}
+	if err := set.ValidateBosses(bosses); err != nil {
+		t.Fatalf("rejected a defined boss: %v", err)
+	}
+
+	set.Waves[1].Bosses = []WaveBoss{{BossID: "hive_qeen"}}
+	if err := set.ValidateBosses(bosses); !errors.Is(err, ErrUnknownBoss) {
+		t.Fatalf("got %v, want %v", err, ErrUnknownBoss)
+	}
+}
+
+func TestSetWaveDataRejectsUnknownBosses(t *testing.T) {
+	before := GetWaveSet()
+
+	set := testWaveSet()
+	set.Waves[1].Bosses = []WaveBoss{{BossID: "hive_qeen"}}
+	if err := SetWaveData(nil, set); !errors.Is(err, ErrUnknownBoss) {
+		t.Fatalf("got %v, want %v", err, ErrUnknownBoss)
+	}
+
+	// A boss set missing the bosses of the waves in use is rejected too
+	if err := SetWaveData(&BossSet{}, nil); !errors.Is(err, ErrUnknownBoss) {
+		t.Fatalf("got %v, want %v", err, ErrUnknownBoss)
+	}
+
+	if GetWaveSet() != before {
+		t.Fatal("a rejected wave set replaced the one in use")
+	}
+}
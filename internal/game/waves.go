@@ -1,125 +1,165 @@
 package game
 
 import (
+	"fmt"
 	"math"
-	"math/rand"
+	"sort"
 	"time"
 
 	"realtime-game-backend/internal/models"
 )
 
-// CreateEnemyWave creates a new enemy wave for a round
-func CreateEnemyWave(round int) models.EnemyWave {
-	wave := models.EnemyWave{
-		ID:      GenerateID(),
-		Round:   round,
-		Enemies: generateEnemies(round),
-		Path:    generatePath(),
-		Status:  "pending",
-		StartAt: time.Now().Add(5*time.Second).UnixNano() / int64(time.Millisecond),
+// CreateEnemyWave creates the enemy wave described by a wave definition on a map's lanes.
+// If m is nil the default map is used.
+func CreateEnemyWave(def WaveDefinition, m *MapDefinition) models.EnemyWave {
+	if m == nil {
+		m = mapRegistry[DefaultMapID]
 	}
 
+	lanes := m.LanePaths()
+	wave := newWave(def.Level, lanes)
+	wave.Enemies = buildEnemies(wave.ID, def, waveSet.Scaling, lanes)
 	return wave
 }
 
-// generateEnemies generates enemies for a wave based on the round
-func generateEnemies(round int) []models.Enemy {
+// newWave creates an empty wave for a level on a set of lanes
+func newWave(level int, lanes []models.Lane) models.EnemyWave {
+	return models.EnemyWave{
+		ID:         GenerateID(),
+		Round:      level,
+		Level:      level,
+		Path:       lanes[0].Path,
+		FlyingPath: lanes[0].FlyingPath,
+		Lanes:      lanes,
+		Status:     "pending",
+		StartAt:    time.Now().Add(5*time.Second).UnixNano() / int64(time.Millisecond),
+	}
+}
+
+// buildEnemies creates the enemies described by a wave definition.
+// Enemies in each group take turns between the lanes.
+func buildEnemies(waveID string, def WaveDefinition, scaling WaveScaling, lanes []models.Lane) []models.Enemy {
 	var enemies []models.Enemy
+	enemyTypes := models.GetEnemyTypes()
 
-	// Base number of enemies - increased scaling
-	baseEnemies := 5 + round*3 // Increased from round*2
+	// Scale enemy stats based on the wave level
+	levelHealth := 1.0 + float64(def.Level-1)*scaling.HealthPerLevel
+	levelSpeed := 1.0 + float64(def.Level-1)*scaling.SpeedPerLevel
+	levelGold := 1.0 + float64(def.Level-1)*scaling.GoldPerLevel
 
-	// Enemy type probabilities based on round - stronger enemies appear earlier
-	basicProb := 1.0
-	fastProb := 0.0
-	tankProb := 0.0
-	bossProb := 0.0
+	newEnemy := func(enemyType string, lane int, spawnAt int64, healthMultiplier, speedMultiplier, goldMultiplier float64, extra *models.EnemyAbilities) models.Enemy {
+		base := enemyTypes[enemyType]
+		if extra != nil {
+			base.Abilities = combineAbilities(base.Abilities, *extra)
+		}
+		health := int(float64(base.Health) * levelHealth * orOne(healthMultiplier))
+		shield := int(float64(base.Abilities.Shield) * levelHealth * orOne(healthMultiplier))
 
-	if round >= 2 { // Reduced from round 3
-		basicProb = 0.7
-		fastProb = 0.3
+		return models.Enemy{
+			ID:        fmt.Sprintf("%s-%d", waveID, len(enemies)),
+			Type:      enemyType,
+			Health:    health,
+			MaxHealth: health,
+			Speed:     base.Speed * levelSpeed * orOne(speedMultiplier),
+			Damage:    base.Damage,
+			Gold:      int(float64(base.Gold) * levelGold * orOne(goldMultiplier)),
+			X:         lanes[lane].Path[0].X,
+			Y:         lanes[lane].Path[0].Y,
+			PathIndex: 0,
+			Lane:      lane,
+			Active:    false, // Activated when the spawn time arrives
+			SpawnAt:   spawnAt,
+			Abilities: base.Abilities,
+			Shield:    shield,
+			MaxShield: shield,
+
+			Resistances: base.Resistances,
+		}
 	}
 
-	if round >= 4 { // Reduced from round 5
-		basicProb = 0.6
-		fastProb = 0.3
-		tankProb = 0.1
+	for _, group := range def.Groups {
+		for i := 0; i < group.Count; i++ {
+			spawnAt := group.Delay + int64(i)*group.Interval
+			enemies = append(enemies, newEnemy(group.EnemyType, i%len(lanes), spawnAt, group.HealthMultiplier, group.SpeedMultiplier, group.GoldMultiplier, group.Abilities))
+		}
 	}
 
-	if round >= 7 { // Reduced from round 10
-		basicProb = 0.5
-		fastProb = 0.3
-		tankProb = 0.15
-		bossProb = 0.05
-	}
+	for i, boss := range def.Bosses {
+		lane := i % len(lanes)
+		if boss.BossID == "" {
+			enemies = append(enemies, newEnemy(boss.EnemyType, lane, boss.Delay, boss.HealthMultiplier, boss.SpeedMultiplier, boss.GoldMultiplier, nil))
+			continue
+		}
 
-	if round >= 10 { // Added new tier for later rounds
-		basicProb = 0.4
-		fastProb = 0.3
-		tankProb = 0.2
-		bossProb = 0.1
+		// Scripted bosses take their stats and rewards from the boss definition
+		// SetWaveData only accepts waves whose bosses are all defined
+		bossDef, ok := bossSet.Get(boss.BossID)
+		if !ok {
+			continue
+		}
+		health := int(float64(bossDef.Health) * levelHealth * orOne(boss.HealthMultiplier))
+		shield := int(float64(bossDef.Abilities.Shield) * levelHealth * orOne(boss.HealthMultiplier))
+
+		enemies = append(enemies, models.Enemy{
+			ID:        fmt.Sprintf("%s-%d", waveID, len(enemies)),
+			Type:      "boss",
+			BossID:    bossDef.ID,
+			Health:    health,
+			MaxHealth: health,
+			Speed:     bossDef.Speed * levelSpeed * orOne(boss.SpeedMultiplier),
+			Damage:    bossDef.Damage,
+			Gold:      int(float64(bossDef.Rewards.Gold) * orOne(boss.GoldMultiplier)),
+			Score:     bossDef.Rewards.Score,
+			X:         lanes[lane].Path[0].X,
+			Y:         lanes[lane].Path[0].Y,
+			Lane:      lane,
+			Active:    false,
+			SpawnAt:   boss.Delay,
+			Abilities: bossDef.Abilities,
+			Shield:    shield,
+			MaxShield: shield,
+
+			Resistances: bossDef.Resistances,
+		})
 	}
 
-	// Generate enemies
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	enemyTypes := models.GetEnemyTypes()
+	return enemies
+}
 
-	for i := 0; i < baseEnemies; i++ {
-		var enemyType string
-		roll := r.Float64()
+// SpawnSchedule returns the arrival times of every enemy in a wave, in spawn order
+func SpawnSchedule(wave models.EnemyWave) []models.SpawnEntry {
+	schedule := make([]models.SpawnEntry, 0, len(wave.Enemies))
+	for _, enemy := range wave.Enemies {
+		schedule = append(schedule, models.SpawnEntry{
+			EnemyID:   enemy.ID,
+			EnemyType: enemy.Type,
+			SpawnAt:   enemy.SpawnAt,
+		})
+	}
 
-		switch {
-		case roll < bossProb:
-			enemyType = "boss"
-		case roll < bossProb+tankProb:
-			enemyType = "tank"
-		case roll < bossProb+tankProb+fastProb:
-			enemyType = "fast"
-		case roll < bossProb+tankProb+fastProb+basicProb:
-			enemyType = "basic"
-		default:
-			enemyType = "basic" // Fallback
-		}
+	sort.SliceStable(schedule, func(i, j int) bool {
+		return schedule[i].SpawnAt < schedule[j].SpawnAt
+	})
 
-		// Scale enemy health based on round - increased scaling
-		healthMultiplier := 1.0 + float64(round-1)*0.2 // Increased from 0.1
+	return schedule
+}
 
-		enemy := models.Enemy{
-			ID:        GenerateID(),
-			Type:      enemyType,
-			Health:    int(float64(enemyTypes[enemyType].Health) * healthMultiplier),
-			MaxHealth: int(float64(enemyTypes[enemyType].Health) * healthMultiplier),
-			Speed:     enemyTypes[enemyType].Speed,
-			Damage:    enemyTypes[enemyType].Damage,
-			Gold:      enemyTypes[enemyType].Gold,
-			X:         0,
-			Y:         0,
-			PathIndex: 0,
-			Active:    true,
+// SpawnDueEnemies activates every enemy whose spawn time has arrived
+func SpawnDueEnemies(wave models.EnemyWave, elapsed int64) models.EnemyWave {
+	for i, enemy := range wave.Enemies {
+		if enemy.Spawned || enemy.SpawnAt > elapsed {
+			continue
 		}
 
-		enemies = append(enemies, enemy)
+		start := PathFor(wave, enemy)[0]
+		wave.Enemies[i].Spawned = true
+		wave.Enemies[i].Active = true
+		wave.Enemies[i].PathIndex = 0
+		wave.Enemies[i].X = start.X
+		wave.Enemies[i].Y = start.Y
 	}
 
-	return enemies
-}
-
-// generatePath generates a path for enemies to follow
-func generatePath() []models.Point {
-	// This is a simplified path generation
-	// In a real game, this would be more complex and possibly map-specific
-	return []models.Point{
-		{X: 0, Y: 0},
-		{X: 100, Y: 0},
-		{X: 100, Y: 100},
-		{X: 200, Y: 100},
-		{X: 200, Y: 200},
-		{X: 300, Y: 200},
-		{X: 300, Y: 300},
-		{X: 400, Y: 300},
-		{X: 400, Y: 400},
-		{X: 500, Y: 400},
-	}
+	return wave
 }
 
 // UpdateEnemyPositions updates the positions of enemies along the path
@@ -129,15 +169,16 @@ func UpdateEnemyPositions(wave models.EnemyWave, deltaTime float64) models.Enemy
 			continue
 		}
 
-		// Get current and next points on the path
-		if enemy.PathIndex >= len(wave.Path)-1 {
+		// Get current and next points on the enemy's path
+		path := PathFor(wave, enemy)
+		if enemy.PathIndex >= len(path)-1 {
 			// Enemy reached the end of the path
 			wave.Enemies[i].Active = false
 			continue
 		}
 
-		currentPoint := wave.Path[enemy.PathIndex]
-		nextPoint := wave.Path[enemy.PathIndex+1]
+		currentPoint := path[enemy.PathIndex]
+		nextPoint := path[enemy.PathIndex+1]
 
 		// Calculate direction vector
 		dx := nextPoint.X - currentPoint.X
@@ -151,7 +192,7 @@ func UpdateEnemyPositions(wave models.EnemyWave, deltaTime float64) models.Enemy
 		}
 
 		// Calculate movement distance
-		moveDistance := enemy.Speed * deltaTime
+		moveDistance := enemy.Speed * orOne(enemy.Slow) * deltaTime
 
 		// Calculate new position
 		newX := enemy.X + dx*moveDistance
@@ -178,10 +219,10 @@ func distance(p1, p2 models.Point) float64 {
 	return math.Sqrt(math.Pow(p2.X-p1.X, 2) + math.Pow(p2.Y-p1.Y, 2))
 }
 
-// IsWaveComplete checks if a wave is complete (all enemies are inactive)
+// IsWaveComplete checks if a wave is complete (all enemies have spawned and are inactive)
 func IsWaveComplete(wave models.EnemyWave) bool {
 	for _, enemy := range wave.Enemies {
-		if enemy.Active {
+		if enemy.Active || !enemy.Spawned {
 			return false
 		}
 	}
@@ -203,7 +244,7 @@ func GetActiveEnemies(wave models.EnemyWave) []models.Enemy {
 func CalculateWaveDamage(wave models.EnemyWave) int {
 	damage := 0
 	for _, enemy := range wave.Enemies {
-		if !enemy.Active && enemy.PathIndex >= len(wave.Path)-1 {
+		if !enemy.Active && enemy.Health > 0 && enemy.PathIndex >= len(PathFor(wave, enemy))-1 {
 			damage += enemy.Damage
 		}
 	}
@@ -220,3 +261,23 @@ func CalculateWaveGold(wave models.EnemyWave) int {
 	}
 	return gold
 }
+
+// CalculateWaveScore calculates the score earned from killing enemies in a wave.
+// Enemies are worth 10 points per wave level and bosses 50, unless the boss defines its own reward.
+func CalculateWaveScore(wave models.EnemyWave) int {
+	score := 0
+	for _, enemy := range wave.Enemies {
+		if enemy.Active || enemy.Health > 0 {
+			continue
+		}
+		switch {
+		case enemy.Score > 0:
+			score += enemy.Score
+		case enemy.Type == "boss":
+			score += 50 * wave.Level
+		default:
+			score += 10 * wave.Level
+		}
+	}
+	return score
+}
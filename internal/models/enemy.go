@@ -13,6 +13,53 @@ type Enemy struct {
 	Y         float64 `json:"y"`         // Y position
 	PathIndex int     `json:"pathIndex"` // Current index in the path
 	Active    bool    `json:"active"`    // Whether the enemy is active
+	SpawnAt   int64   `json:"spawnAt"`   // Milliseconds after the wave starts when the enemy spawns
+	Spawned   bool    `json:"spawned"`   // Whether the enemy has entered the path
+	Lane      int     `json:"lane"`      // Index of the lane the enemy follows in the wave
+
+	// Path overrides the lane once the enemy has been re-routed around new towers in maze mode
+	Path []Point `json:"path,omitempty"`
+
+	Abilities EnemyAbilities `json:"abilities"`           // Special abilities of the enemy type
+	Shield    int            `json:"shield,omitempty"`    // Current shield, absorbed before health
+	MaxShield int            `json:"maxShield,omitempty"` // Maximum shield
+
+	// Scripted bosses
+	BossID   string   `json:"bossId,omitempty"`   // Boss definition, empty for regular enemies
+	Phase    int      `json:"phase,omitempty"`    // Number of boss phases entered so far
+	ImmuneTo []string `json:"immuneTo,omitempty"` // Tower types that can't damage the enemy
+	Score    int      `json:"score,omitempty"`    // Score reward for killing
+
+	// Slow is the speed multiplier of the strongest slow on the enemy, 0 when it isn't slowed.
+	// It wears off SlowedUntil milliseconds after the wave starts.
+	Slow        float64 `json:"slow,omitempty"`
+	SlowedUntil int64   `json:"slowedUntil,omitempty"`
+
+	// Damage multipliers by damage type: below 1 resists, above 1 is a weakness
+	Resistances map[string]float64 `json:"resistances,omitempty"`
+
+	// Fractional health and shield regenerated but not yet applied
+	RegenProgress  float64 `json:"-"`
+	ShieldProgress float64 `json:"-"`
+}
+
+// EnemyAbilities represents the special abilities of an enemy type
+type EnemyAbilities struct {
+	Armor       int     `json:"armor,omitempty"`       // Flat damage reduction per hit
+	Shield      int     `json:"shield,omitempty"`      // Shield that absorbs damage before health
+	ShieldRegen float64 `json:"shieldRegen,omitempty"` // Shield regenerated per second
+	Regen       float64 `json:"regen,omitempty"`       // Health regenerated per second
+	SplitCount  int     `json:"splitCount,omitempty"`  // Number of children spawned on death
+	SplitType   string  `json:"splitType,omitempty"`   // Enemy type of the children
+	Flying      bool    `json:"flying,omitempty"`      // Ignores ground-only towers and takes a shortcut path
+	Invisible   bool    `json:"invisible,omitempty"`   // Can only be targeted by towers with detection
+}
+
+// SpawnEntry represents a scheduled enemy arrival
+type SpawnEntry struct {
+	EnemyID   string `json:"enemyId"`
+	EnemyType string `json:"enemyType"`
+	SpawnAt   int64  `json:"spawnAt"` // Milliseconds after the wave starts
 }
 
 // EnemyWave represents a wave of enemies
@@ -24,6 +71,19 @@ type EnemyWave struct {
 	Path    []Point `json:"path"`    // Path for enemies to follow
 	Status  string  `json:"status"`  // "pending", "active", "completed"
 	StartAt int64   `json:"startAt"` // Timestamp when the wave starts
+
+	// Shortcut path taken by flying enemies
+	FlyingPath []Point `json:"flyingPath,omitempty"`
+
+	// Lanes of the map the wave is fought on; Path and FlyingPath mirror the first lane
+	Lanes []Lane `json:"lanes,omitempty"`
+}
+
+// Lane represents one route enemies can take from a spawn to the base
+type Lane struct {
+	ID         string  `json:"id"`
+	Path       []Point `json:"path"`
+	FlyingPath []Point `json:"flyingPath"` // Shortcut path taken by flying enemies
 }
 
 // Point represents a 2D point
@@ -34,11 +94,15 @@ type Point struct {
 
 // EnemyType represents an enemy type with base stats
 type EnemyType struct {
-	Type   string  `json:"type"`
-	Health int     `json:"health"`
-	Speed  float64 `json:"speed"`
-	Damage int     `json:"damage"`
-	Gold   int     `json:"gold"`
+	Type      string         `json:"type"`
+	Health    int            `json:"health"`
+	Speed     float64        `json:"speed"`
+	Damage    int            `json:"damage"`
+	Gold      int            `json:"gold"`
+	Abilities EnemyAbilities `json:"abilities"`
+
+	// Damage multipliers by damage type: below 1 resists, above 1 is a weakness
+	Resistances map[string]float64 `json:"resistances,omitempty"`
 }
 
 // GetEnemyTypes returns all enemy types
@@ -52,18 +116,20 @@ func GetEnemyTypes() map[string]EnemyType {
 			Gold:   6,
 		},
 		"fast": {
-			Type:   "fast",
-			Health: 120,
-			Speed:  1.7,
-			Damage: 2,
-			Gold:   9,
+			Type:        "fast",
+			Health:      120,
+			Speed:       1.7,
+			Damage:      2,
+			Gold:        9,
+			Resistances: map[string]float64{"frost": 1.5, "poison": 0.75},
 		},
 		"tank": {
-			Type:   "tank",
-			Health: 300,
-			Speed:  0.8,
-			Damage: 3,
-			Gold:   12,
+			Type:        "tank",
+			Health:      300,
+			Speed:       0.8,
+			Damage:      3,
+			Gold:        12,
+			Resistances: map[string]float64{"physical": 0.75, "poison": 1.5},
 		},
 		"boss": {
 			Type:   "boss",
@@ -72,5 +138,67 @@ func GetEnemyTypes() map[string]EnemyType {
 			Damage: 5,
 			Gold:   25,
 		},
+		"armored": {
+			Type:        "armored",
+			Health:      220,
+			Speed:       0.8,
+			Damage:      3,
+			Gold:        12,
+			Abilities:   EnemyAbilities{Armor: 6},
+			Resistances: map[string]float64{"physical": 0.6, "fire": 1.25, "arcane": 1.5},
+		},
+		"shielded": {
+			Type:        "shielded",
+			Health:      150,
+			Speed:       1.0,
+			Damage:      2,
+			Gold:        12,
+			Abilities:   EnemyAbilities{Shield: 120, ShieldRegen: 15},
+			Resistances: map[string]float64{"arcane": 1.5, "frost": 0.75},
+		},
+		"regenerator": {
+			Type:        "regenerator",
+			Health:      200,
+			Speed:       0.9,
+			Damage:      2,
+			Gold:        11,
+			Abilities:   EnemyAbilities{Regen: 12},
+			Resistances: map[string]float64{"fire": 1.5, "poison": 0.5},
+		},
+		"splitter": {
+			Type:        "splitter",
+			Health:      200,
+			Speed:       0.9,
+			Damage:      2,
+			Gold:        10,
+			Abilities:   EnemyAbilities{SplitCount: 3, SplitType: "swarmling"},
+			Resistances: map[string]float64{"fire": 1.25, "physical": 0.9},
+		},
+		"swarmling": {
+			Type:        "swarmling",
+			Health:      40,
+			Speed:       1.6,
+			Damage:      1,
+			Gold:        2,
+			Resistances: map[string]float64{"fire": 1.5},
+		},
+		"flyer": {
+			Type:        "flyer",
+			Health:      110,
+			Speed:       1.3,
+			Damage:      2,
+			Gold:        10,
+			Abilities:   EnemyAbilities{Flying: true},
+			Resistances: map[string]float64{"frost": 1.25, "poison": 0.75},
+		},
+		"ghost": {
+			Type:        "ghost",
+			Health:      130,
+			Speed:       1.2,
+			Damage:      2,
+			Gold:        12,
+			Abilities:   EnemyAbilities{Invisible: true},
+			Resistances: map[string]float64{"physical": 0.5, "arcane": 1.75},
+		},
 	}
 }
@@ -47,4 +47,9 @@ type PlayerState struct {
 	IsReady  bool    `json:"isReady"`
 	IsActive bool    `json:"isActive"`
 	LastSeen int64   `json:"lastSeen"`
+
+	// The rest of the player's poker hand, kept in saved games
+	Deck       []Card `json:"deck,omitempty"`
+	DrawCount  int    `json:"drawCount,omitempty"`
+	HandPlayed bool   `json:"handPlayed,omitempty"` // The player played their hand this round
 }
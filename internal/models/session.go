@@ -26,6 +26,30 @@ type GameState struct {
 	StartedAt   int64                   `json:"startedAt"`
 	UpdatedAt   int64                   `json:"updatedAt"`
 	Status      string                  `json:"status"` // "active", "completed", "abandoned"
+
+	// Run settings and progress, so a saved game can be resumed
+	MapID      string         `json:"mapId,omitempty"`
+	Mode       string         `json:"mode,omitempty"`    // "lanes" or "maze"
+	RunType    string         `json:"runType,omitempty"` // "campaign" or "endless"
+	Seed       int64          `json:"seed"`
+	WaveLevel  int            `json:"waveLevel"`  // Level of the last wave started
+	BaseMode   string         `json:"baseMode"`   // "player" or "shared"
+	BaseHealth int            `json:"baseHealth"` // Health of the shared base
+	Towers     []Tower        `json:"towers"`
+	RunStats   []TowerStats   `json:"runStats,omitempty"`
+	Scouting   map[string]int `json:"scouting,omitempty"`   // Last wave level scouted by each player
+	PausesUsed map[string]int `json:"pausesUsed,omitempty"` // Pauses asked for by each player
+	Adaptive   bool           `json:"adaptive,omitempty"`   // Adaptive difficulty is on
+	Assisted   bool           `json:"assisted,omitempty"`   // The director made a wave easier
+}
+
+// SavedGame describes a game saved by a player
+type SavedGame struct {
+	ID        string    `json:"id"`
+	PlayerID  string    `json:"playerId"`
+	RunType   string    `json:"runType"`
+	WaveLevel int       `json:"waveLevel"`
+	CreatedAt time.Time `json:"createdAt"`
 }
 
 // Card represents a playing card
@@ -43,7 +67,8 @@ type Tower struct {
 	ID       string  `json:"id"`
 	PlayerID string  `json:"playerId"`
 	Type     string  `json:"type"`     // "basic", "splash", "sniper", etc.
-	Level    int     `json:"level"`    // 1-3
+	Category string  `json:"category"` // "damage", "support", "economy"
+	Level    int     `json:"level"`    // 1 up to the tower catalog's maxLevel
 	X        float64 `json:"x"`        // X position
 	Y        float64 `json:"y"`        // Y position
 	Range    float64 `json:"range"`    // Attack range
@@ -51,6 +76,50 @@ type Tower struct {
 	Speed    float64 `json:"speed"`    // Attack speed (attacks per second)
 	Cost     int     `json:"cost"`     // Gold cost
 	LastShot int64   `json:"lastShot"` // Timestamp of last shot
+
+	// Damage tower behaviour
+	DamageType   string  `json:"damageType,omitempty"`   // "physical", "fire", "frost", "poison", "arcane"
+	Splash       bool    `json:"splash,omitempty"`       // Hits every enemy in range
+	SlowFactor   float64 `json:"slowFactor,omitempty"`   // Speed multiplier applied to enemies hit
+	SlowDuration int64   `json:"slowDuration,omitempty"` // Milliseconds an enemy stays slowed after a hit
+	GroundOnly   bool    `json:"groundOnly,omitempty"`   // Can't hit flying enemies
+	Detection    bool    `json:"detection,omitempty"`    // Can target invisible enemies
+
+	// Milliseconds of wave time until which the tower can't fire, set by boss phases
+	DisabledUntil int64 `json:"disabledUntil,omitempty"`
+
+	// Support towers buff damage towers within AuraRadius
+	AuraRadius      float64 `json:"auraRadius,omitempty"`
+	AuraRangeBonus  float64 `json:"auraRangeBonus,omitempty"`  // Fractional range bonus, e.g. 0.2 = +20%
+	AuraDamageBonus float64 `json:"auraDamageBonus,omitempty"` // Fractional damage bonus
+	AuraSpeedBonus  float64 `json:"auraSpeedBonus,omitempty"`  // Fractional attack speed bonus
+	AuraDetection   bool    `json:"auraDetection,omitempty"`   // Lets towers in the aura target invisible enemies
+
+	// Economy towers generate gold for their owner each wave
+	GoldPerWave int `json:"goldPerWave,omitempty"`
+}
+
+// TowerStats represents the combat statistics of a tower over a wave or a whole run
+type TowerStats struct {
+	TowerID     string `json:"towerId,omitempty"`
+	TowerType   string `json:"towerType"`
+	PlayerID    string `json:"playerId,omitempty"`
+	DamageDealt int    `json:"damageDealt"` // Damage that reduced enemy health
+	Kills       int    `json:"kills"`
+	ShotsFired  int    `json:"shotsFired"`
+	Overkill    int    `json:"overkill"` // Damage beyond what was needed for a kill
+	Uptime      int64  `json:"uptime"`   // Milliseconds with a target in range
+	Waves       int    `json:"waves"`    // Number of waves the tower took part in
+}
+
+// Add adds another set of statistics to these statistics
+func (s *TowerStats) Add(other TowerStats) {
+	s.DamageDealt += other.DamageDealt
+	s.Kills += other.Kills
+	s.ShotsFired += other.ShotsFired
+	s.Overkill += other.Overkill
+	s.Uptime += other.Uptime
+	s.Waves += other.Waves
 }
 
 // HandRank represents a poker hand rank
@@ -66,3 +135,27 @@ type PokerHand struct {
 	Rank     HandRank `json:"rank"`
 	PlayerID string   `json:"playerId"`
 }
+
+// DifficultyAdjustment records how the adaptive director changed a wave for a player
+type DifficultyAdjustment struct {
+	PlayerID         string  `json:"playerId"`
+	Level            int     `json:"level"`
+	Pressure         float64 `json:"pressure"`         // -1 (struggling, waves get easier) to 1 (thriving, waves get harder)
+	HealthMultiplier float64 `json:"healthMultiplier"` // Applied to every enemy's health
+	CountMultiplier  float64 `json:"countMultiplier"`  // Applied to the size of every enemy group
+	AvgLeakDamage    float64 `json:"avgLeakDamage"`    // Recent base damage per wave
+	AvgHandValue     float64 `json:"avgHandValue"`     // Recent poker hand rank values
+	GoldBanked       int     `json:"goldBanked"`
+	BaseHealth       int     `json:"baseHealth"`
+	Assisted         bool    `json:"assisted"` // The wave was made easier
+
+	// Groups whose enemy type the director changed
+	Swaps []EnemySwap `json:"swaps,omitempty"`
+}
+
+// EnemySwap records the director changing the enemy type of a group in a wave
+type EnemySwap struct {
+	Group int    `json:"group"` // Index of the group in the wave definition
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
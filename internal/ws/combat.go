@@ -0,0 +1,365 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"realtime-game-backend/internal/game"
+	"realtime-game-backend/internal/models"
+)
+
+// Server-side wave simulation timing
+const (
+	simulationTickRate = 50 * time.Millisecond
+	maxWaveDuration    = 10 * time.Minute
+)
+
+// Error definitions
+var (
+	ErrGameOver      = errors.New("the game is over")
+	ErrBaseDestroyed = errors.New("base destroyed")
+	ErrNoPlayers     = errors.New("no player to start the wave")
+)
+
+// startWave starts the room's next wave against the base of ownerID, broadcasts wave_started
+// and simulates the wave on the server. An empty ownerID picks a player whose base still stands.
+func (h *Hub) startWave(state *RoomState, ownerID string) error {
+	connected := h.roomPlayerIDs(state.ID)
+
+	state.Mutex.Lock()
+	if ownerID == "" {
+		ownerID = state.defaultWaveOwner(connected)
+		if ownerID == "" {
+			state.Mutex.Unlock()
+			return ErrNoPlayers
+		}
+	}
+	if state.GameOver {
+		state.Mutex.Unlock()
+		return ErrGameOver
+	}
+	if !state.baseAlive(ownerID) {
+		state.Mutex.Unlock()
+		return ErrBaseDestroyed
+	}
+	if err := game.CanTransition(state.Phase, game.CombatPhase); err != nil {
+		state.Mutex.Unlock()
+		return err
+	}
+
+	// Build the wave for the next level on the room's map
+	level := state.WaveLevel + 1
+	def := state.WaveDefinition(level)
+
+	// Let the adaptive director tune the wave to how the player is doing
+	var adjustment *models.DifficultyAdjustment
+	if state.Director != nil {
+		player := state.Player(ownerID)
+		adjusted, change, ok := state.Director.Adjust(def, ownerID, player.Gold, state.baseHealth(ownerID), startingHealth)
+		if ok {
+			def = adjusted
+			adjustment = &change
+			state.Adjustments = append(state.Adjustments, change)
+			state.Assisted = state.Assisted || change.Assisted
+		}
+	}
+	var wave models.EnemyWave
+	var err error
+	if state.Mode == game.MazeMode {
+		wave, err = game.CreateMazeWave(def, state.Map, state.Towers)
+	} else {
+		wave = game.CreateEnemyWave(def, state.Map)
+	}
+	if err != nil {
+		state.Mutex.Unlock()
+		return err
+	}
+	wave.Status = "active"
+	wave.StartAt = time.Now().UnixNano() / int64(time.Millisecond)
+	state.WaveLevel = level
+
+	// Pay out gold generated by economy towers
+	bankGold := game.CalculateBankGold(state.Towers)
+	for playerID, gold := range bankGold {
+		state.Player(playerID).Gold += gold
+	}
+	phaseChange, _ := state.transition(game.CombatPhase)
+	sessionID := state.SessionID
+	runType := state.RunType
+	state.Mutex.Unlock()
+
+	log.Printf("Starting wave level %d for player %s in room %s", level, ownerID, state.ID)
+
+	if adjustment != nil {
+		log.Printf("Adaptive difficulty for player %s at wave %d: pressure %.2f, health x%.2f, count x%.2f",
+			ownerID, level, adjustment.Pressure, adjustment.HealthMultiplier, adjustment.CountMultiplier)
+		go h.recordDifficultyAdjustment(sessionID, *adjustment)
+	}
+
+	// Create response payload
+	payload := map[string]interface{}{
+		"wave":     wave,
+		"schedule": game.SpawnSchedule(wave),
+		"bankGold": bankGold,
+		"runType":  runType,
+		"playerId": ownerID,
+	}
+	if adjustment != nil {
+		payload["difficulty"] = adjustment
+	}
+
+	// Marshal payload to JSON
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Sending wave_started response to room %s with %d enemies", state.ID, len(wave.Enemies))
+
+	h.BroadcastToRoom(state.ID, &Message{
+		Type:     "wave_started",
+		Payload:  payloadJSON,
+		SenderID: "server",
+	})
+
+	if phaseChange != nil {
+		h.phaseChanged(state, phaseChange)
+	}
+
+	// Run the wave on the server to track combat statistics
+	go h.runWave(state, wave, ownerID)
+	return nil
+}
+
+// defaultWaveOwner picks the player a wave is started for when nobody started it:
+// the first connected player, then the first other player, whose base still stands.
+// The caller must hold the room mutex.
+func (r *RoomState) defaultWaveOwner(connected []string) string {
+	others := make([]string, 0, len(r.Players))
+	for playerID := range r.Players {
+		others = append(others, playerID)
+	}
+	sort.Strings(connected)
+	sort.Strings(others)
+
+	for _, playerID := range append(connected, others...) {
+		if r.baseAlive(playerID) {
+			return playerID
+		}
+	}
+	return ""
+}
+
+// runWave simulates a wave started by ownerID on the server.
+// Enemies that reach the base damage it, and wave_completed is broadcast with tower statistics when the wave ends.
+func (h *Hub) runWave(state *RoomState, wave models.EnemyWave, ownerID string) {
+	state.Mutex.Lock()
+	if state.Simulation != nil {
+		state.Mutex.Unlock()
+		log.Printf("Wave already running in room %s, not simulating wave %s", state.ID, wave.ID)
+		return
+	}
+	sim := game.NewSimulation(state.Towers, wave)
+	if state.Mode == game.MazeMode {
+		sim.Maze = state.Map
+	}
+	state.Simulation = sim
+
+	// The first wave starts the room's game session
+	newSession := state.SessionID == ""
+	if newSession {
+		state.SessionID = generateID()
+	}
+	sessionID := state.SessionID
+	state.Mutex.Unlock()
+
+	if newSession {
+		h.startGameSession(state.ID, sessionID)
+	}
+
+	ticker := time.NewTicker(simulationTickRate)
+	defer ticker.Stop()
+
+	basesLost := false
+	for {
+		select {
+		case <-state.closed:
+			// Nobody is left in the room, paused or not
+			h.abandonWave(state, sim)
+			return
+		case <-ticker.C:
+		}
+
+		state.Mutex.Lock()
+
+		// The wave stands still while the game is paused
+		if state.Paused {
+			state.Mutex.Unlock()
+			continue
+		}
+
+		sim.Tick(simulationTickRate)
+		phaseChanges := sim.DrainBossPhaseChanges()
+
+		// Kills pay the player who started the wave as they happen, so the gold can be spent mid-wave
+		if bounty := sim.DrainBounty(); bounty > 0 {
+			state.Player(ownerID).Gold += bounty
+		}
+
+		// Enemies that reached the base damage it
+		leaks := sim.DrainLeaks()
+		var damage BaseDamage
+		if len(leaks) > 0 {
+			damage, basesLost = state.damageBase(ownerID, leaks)
+		}
+
+		done := sim.Done() || basesLost || time.Duration(sim.Elapsed)*time.Millisecond >= maxWaveDuration
+		state.Mutex.Unlock()
+
+		for _, change := range phaseChanges {
+			h.broadcastBossPhaseChange(state.ID, change)
+		}
+
+		if len(leaks) > 0 {
+			h.broadcastBaseDamage(state.ID, damage)
+		}
+
+		if done {
+			break
+		}
+	}
+
+	// The room may have emptied during the last tick
+	if state.isClosed() {
+		h.abandonWave(state, sim)
+		return
+	}
+	h.completeWave(state, sim, ownerID, basesLost)
+}
+
+// abandonWave stops simulating a wave in a room nobody is left in,
+// without recording its statistics or broadcasting its end
+func (h *Hub) abandonWave(state *RoomState, sim *game.Simulation) {
+	log.Printf("Room %s closed, abandoning wave %s after %dms", state.ID, sim.Wave.ID, sim.Elapsed)
+
+	state.Mutex.Lock()
+	state.Simulation = nil
+	state.Mutex.Unlock()
+}
+
+// completeWave records the statistics and score of a finished wave, broadcasts wave_completed
+// and ends the game if every base was destroyed or the campaign was cleared
+func (h *Hub) completeWave(state *RoomState, sim *game.Simulation, ownerID string, basesLost bool) {
+	state.Mutex.Lock()
+	waveStats := sim.TowerStats()
+
+	// Fold the wave statistics into the run totals
+	runStats := make([]models.TowerStats, 0, len(waveStats))
+	for _, stats := range waveStats {
+		total, ok := state.RunStats[stats.TowerID]
+		if !ok {
+			total = &models.TowerStats{
+				TowerID:   stats.TowerID,
+				TowerType: stats.TowerType,
+				PlayerID:  stats.PlayerID,
+			}
+			state.RunStats[stats.TowerID] = total
+		}
+		total.Add(stats)
+		runStats = append(runStats, *total)
+	}
+
+	// Kills score for the player who started the wave, who was paid for them during the wave
+	score := game.CalculateWaveScore(sim.Wave)
+	owner := state.Player(ownerID)
+	owner.Score += score
+	totalScore := owner.Score
+	gold := game.CalculateWaveGold(sim.Wave)
+	totalGold := owner.Gold
+
+	// Feed the base damage to the adaptive director
+	if state.Director != nil {
+		state.Director.RecordWave(ownerID, game.CalculateWaveDamage(sim.Wave))
+	}
+
+	// A new round of cards starts unless the game is over
+	reason := state.gameOverReason(sim.Wave.Level, basesLost)
+	nextPhase := game.CardsPhase
+	if reason != "" {
+		state.GameOver = true
+		state.FinalLevel = sim.Wave.Level
+		nextPhase = game.EndPhase
+	}
+	phaseChange, err := state.transition(nextPhase)
+	if err != nil {
+		log.Printf("Error ending wave %s in room %s: %v", sim.Wave.ID, state.ID, err)
+	}
+
+	state.Simulation = nil
+	state.Mutex.Unlock()
+
+	log.Printf("Wave %s completed in room %s after %dms", sim.Wave.ID, state.ID, sim.Elapsed)
+
+	// Persist the per-tower-type summary for balancing
+	if h.Postgres != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := h.Postgres.RecordTowerTypeStats(ctx, game.SummarizeTowerStatsByType(waveStats)); err != nil {
+			log.Printf("Error recording tower type stats: %v", err)
+		}
+	}
+
+	// Create response payload
+	payload := map[string]interface{}{
+		"waveId":     sim.Wave.ID,
+		"level":      sim.Wave.Level,
+		"towerStats": waveStats,
+		"runStats":   runStats,
+		"playerId":   ownerID,
+		"score":      score,
+		"totalScore": totalScore,
+		"gold":       gold,
+		"totalGold":  totalGold,
+	}
+
+	// Marshal payload to JSON
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error marshaling payload: %v", err)
+		return
+	}
+
+	h.BroadcastToRoom(state.ID, &Message{
+		Type:     "wave_completed",
+		Payload:  payloadJSON,
+		SenderID: "server",
+	})
+
+	if phaseChange != nil {
+		h.phaseChanged(state, phaseChange)
+	}
+
+	if reason != "" {
+		h.endGame(state, reason, sim.Wave.Level)
+	}
+}
+
+// broadcastBossPhaseChange broadcasts boss_phase_changed to a room
+func (h *Hub) broadcastBossPhaseChange(roomID string, change game.BossPhaseChange) {
+	payloadJSON, err := json.Marshal(change)
+	if err != nil {
+		log.Printf("Error marshaling payload: %v", err)
+		return
+	}
+
+	h.BroadcastToRoom(roomID, &Message{
+		Type:     "boss_phase_changed",
+		Payload:  payloadJSON,
+		SenderID: "server",
+	})
+}
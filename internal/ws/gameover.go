@@ -0,0 +1,312 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"realtime-game-backend/internal/db"
+	"realtime-game-backend/internal/game"
+	"realtime-game-backend/internal/models"
+)
+
+// Base modes
+const (
+	PlayerBase = "player" // Every player defends their own base against the waves they start
+	SharedBase = "shared" // The whole room defends one base
+)
+
+// Reasons a game ends
+const (
+	baseDestroyed   = "base_destroyed"
+	campaignCleared = "campaign_cleared"
+)
+
+// Error definitions
+var (
+	ErrGameNotOver         = errors.New("the game isn't over")
+	ErrScoreSubmitted      = errors.New("score already submitted")
+	ErrNoScore             = errors.New("no score to submit")
+	ErrLeaderboardDisabled = errors.New("the leaderboard needs persistence")
+)
+
+// BaseDamage describes the damage done to a base by the enemies that reached it in one tick
+type BaseDamage struct {
+	BaseMode string   `json:"baseMode"`
+	PlayerID string   `json:"playerId,omitempty"` // Owner of the damaged base, empty for the shared base
+	Damage   int      `json:"damage"`
+	Health   int      `json:"health"` // Health left after the damage
+	EnemyIDs []string `json:"enemyIds"`
+}
+
+// PlayerResult describes how a player finished a game
+type PlayerResult struct {
+	PlayerID string `json:"playerId"`
+	Username string `json:"username,omitempty"`
+	Score    int    `json:"score"`
+	Health   int    `json:"health"`
+	Won      bool   `json:"won"`
+}
+
+// damageBase deducts the damage of leaked enemies from the base defending a wave started by ownerID.
+// It reports whether every base has been destroyed. The caller must hold the room mutex.
+func (r *RoomState) damageBase(ownerID string, leaks []models.Enemy) (BaseDamage, bool) {
+	damage := BaseDamage{BaseMode: r.BaseMode}
+	for _, enemy := range leaks {
+		damage.Damage += enemy.Damage
+		damage.EnemyIDs = append(damage.EnemyIDs, enemy.ID)
+	}
+
+	if r.BaseMode == SharedBase {
+		r.BaseHealth = max(r.BaseHealth-damage.Damage, 0)
+		damage.Health = r.BaseHealth
+		return damage, r.BaseHealth == 0
+	}
+
+	player := r.Player(ownerID)
+	player.Health = max(player.Health-damage.Damage, 0)
+	damage.PlayerID = ownerID
+	damage.Health = player.Health
+
+	// The game goes on while any player still has a base
+	for _, other := range r.Players {
+		if other.Health > 0 {
+			return damage, false
+		}
+	}
+	return damage, true
+}
+
+// baseHealth returns the health of the base defending a player's waves.
+// The caller must hold the room mutex.
+func (r *RoomState) baseHealth(playerID string) int {
+	if r.BaseMode == SharedBase {
+		return r.BaseHealth
+	}
+	return r.Player(playerID).Health
+}
+
+// baseAlive checks if the base defending a player's waves still stands.
+// The caller must hold the room mutex.
+func (r *RoomState) baseAlive(playerID string) bool {
+	return r.baseHealth(playerID) > 0
+}
+
+// gameOverReason returns why the game ends after a wave, or an empty string if it goes on.
+// The caller must hold the room mutex.
+func (r *RoomState) gameOverReason(level int, basesLost bool) string {
+	if basesLost {
+		return baseDestroyed
+	}
+	if r.RunType == game.CampaignRun && level >= game.GetWaveSet().FinalLevel() {
+		return campaignCleared
+	}
+	return ""
+}
+
+// scoreCategory returns the leaderboard category of the room's run
+func (r *RoomState) scoreCategory() string {
+	if r.RunType == game.EndlessRun {
+		return db.EndlessCategory
+	}
+	return db.CampaignCategory
+}
+
+// broadcastBaseDamage broadcasts base_damaged to a room
+func (h *Hub) broadcastBaseDamage(roomID string, damage BaseDamage) {
+	payloadJSON, err := json.Marshal(damage)
+	if err != nil {
+		log.Printf("Error marshaling payload: %v", err)
+		return
+	}
+
+	h.BroadcastToRoom(roomID, &Message{
+		Type:     "base_damaged",
+		Payload:  payloadJSON,
+		SenderID: "server",
+	})
+}
+
+// startGameSession records the start of a room's game in Postgres
+func (h *Hub) startGameSession(roomID, sessionID string) {
+	if h.Postgres == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Postgres.CreateGameSession(ctx, sessionID, roomID); err != nil {
+		log.Printf("Error creating game session for room %s: %v", roomID, err)
+	}
+}
+
+// recordDifficultyAdjustment logs an adaptive difficulty change in the room's game session
+func (h *Hub) recordDifficultyAdjustment(sessionID string, adjustment models.DifficultyAdjustment) {
+	if h.Postgres == nil || sessionID == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Postgres.RecordDifficultyAdjustment(ctx, sessionID, adjustment); err != nil {
+		log.Printf("Error recording difficulty adjustment for session %s: %v", sessionID, err)
+	}
+}
+
+// endGame ends a room's game, records the results and broadcasts game_over
+func (h *Hub) endGame(state *RoomState, reason string, level int) {
+	state.Mutex.Lock()
+	won := reason == campaignCleared
+	results := make([]PlayerResult, 0, len(state.Players))
+	for _, player := range state.Players {
+		results = append(results, PlayerResult{
+			PlayerID: player.PlayerID,
+			Username: player.Username,
+			Score:    player.Score,
+			Health:   state.baseHealth(player.PlayerID),
+			Won:      won && state.baseAlive(player.PlayerID),
+		})
+	}
+	sessionID := state.SessionID
+	runType := state.RunType
+	assisted := state.Assisted
+	category := state.scoreCategory()
+	state.Mutex.Unlock()
+
+	// Highest score first
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	log.Printf("Game over in room %s at wave %d: %s", state.ID, level, reason)
+
+	h.recordGameResults(sessionID, results)
+
+	// Create response payload
+	payload := map[string]interface{}{
+		"reason":   reason,
+		"won":      won,
+		"level":    level,
+		"runType":  runType,
+		"assisted": assisted,
+		"players":  results,
+		// Players can send submit_score to put their score on the leaderboard
+		"scoreSubmission": map[string]interface{}{
+			"available": h.Postgres != nil,
+			"category":  category,
+			"wave":      level,
+		},
+	}
+
+	// Marshal payload to JSON
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error marshaling payload: %v", err)
+		return
+	}
+
+	h.BroadcastToRoom(state.ID, &Message{
+		Type:     "game_over",
+		Payload:  payloadJSON,
+		SenderID: "server",
+	})
+}
+
+// recordGameResults stores the players' scores, updates their stats and ends the game session in Postgres
+func (h *Hub) recordGameResults(sessionID string, results []PlayerResult) {
+	if h.Postgres == nil || sessionID == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, result := range results {
+		if result.PlayerID == "" {
+			continue
+		}
+
+		// Players are created on demand, since the server has no registration
+		username := result.Username
+		if username == "" {
+			username = result.PlayerID
+		}
+		if err := h.Postgres.CreatePlayer(ctx, result.PlayerID, username); err != nil {
+			log.Printf("Error creating player %s: %v", result.PlayerID, err)
+			continue
+		}
+		if err := h.Postgres.AddPlayerToSession(ctx, generateID(), result.PlayerID, sessionID); err != nil {
+			log.Printf("Error adding player %s to session %s: %v", result.PlayerID, sessionID, err)
+			continue
+		}
+		if err := h.Postgres.UpdatePlayerScore(ctx, result.PlayerID, sessionID, result.Score); err != nil {
+			log.Printf("Error updating score of player %s: %v", result.PlayerID, err)
+		}
+		if err := h.Postgres.UpdatePlayerStats(ctx, result.PlayerID, result.Won, result.Score); err != nil {
+			log.Printf("Error updating stats of player %s: %v", result.PlayerID, err)
+		}
+	}
+
+	if err := h.Postgres.EndGameSession(ctx, sessionID); err != nil {
+		log.Printf("Error ending game session %s: %v", sessionID, err)
+	}
+}
+
+// submitScore saves a player's final score to the leaderboard of the room's run type and returns the result
+func (h *Hub) submitScore(client *Client, name string) (map[string]interface{}, error) {
+	state := h.GetRoomState(client.RoomID)
+	state.Mutex.Lock()
+	if !state.GameOver {
+		state.Mutex.Unlock()
+		return nil, ErrGameNotOver
+	}
+	if state.submittedScores[client.PlayerID] {
+		state.Mutex.Unlock()
+		return nil, ErrScoreSubmitted
+	}
+	score := state.Player(client.PlayerID).Score
+	wave := state.FinalLevel
+	assisted := state.Assisted
+	category := state.scoreCategory()
+	state.Mutex.Unlock()
+
+	if h.Postgres == nil {
+		return nil, ErrLeaderboardDisabled
+	}
+	if score <= 0 {
+		return nil, ErrNoScore
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var isHighScore bool
+	var err error
+	if category == db.EndlessCategory {
+		isHighScore, err = h.Postgres.SaveEndlessHighScore(ctx, name, wave, score, assisted)
+	} else {
+		isHighScore, err = h.Postgres.SaveHighScore(ctx, name, score, assisted)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Only a saved score counts, so the player can try again if the database was unavailable
+	state.Mutex.Lock()
+	state.submittedScores[client.PlayerID] = true
+	state.Mutex.Unlock()
+
+	return map[string]interface{}{
+		"name":        name,
+		"score":       score,
+		"wave":        wave,
+		"category":    category,
+		"isHighScore": isHighScore,
+		"assisted":    assisted,
+	}, nil
+}
@@ -0,0 +1,186 @@
+package ws
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"realtime-game-backend/internal/game"
+	"realtime-game-backend/internal/models"
+)
+
+// Poker hands are dealt with 5 cards and drawn twice
+const (
+	handSize = 5
+	maxDraws = 3
+)
+
+// Error definitions
+var (
+	ErrHandPlayed    = errors.New("hand already played this round")
+	ErrNoHand        = errors.New("no hand dealt")
+	ErrCardNotInHand = errors.New("card not in hand")
+)
+
+// pokerHand is a player's poker hand for one round
+type pokerHand struct {
+	Round     int
+	Cards     []models.Card
+	Deck      []models.Card
+	DrawCount int
+}
+
+// hand returns a player's hand for the current round, starting an empty one in a new round.
+// A hand left unfinished when the room moved on is thrown away. The caller must hold the room mutex.
+func (r *RoomState) hand(playerID string) *pokerHand {
+	hand, ok := r.hands[playerID]
+	if !ok || hand.Round != r.Round {
+		hand = &pokerHand{Round: r.Round}
+		r.hands[playerID] = hand
+	}
+	return hand
+}
+
+// draw deals a new hand from a fresh deck on the first draw, and replaces the cards
+// that aren't held on later draws. It returns the number of cards drawn.
+func (h *pokerHand) draw() int {
+	if h.DrawCount == 0 || h.DrawCount >= maxDraws {
+		h.Cards, h.Deck = game.DealCards(game.ShuffleDeck(game.NewDeck()), handSize)
+		h.DrawCount = 1
+		return len(h.Cards)
+	}
+
+	var held []models.Card
+	for _, card := range h.Cards {
+		if card.Held {
+			held = append(held, card)
+		}
+	}
+
+	drawn, deck := game.DealCards(h.Deck, len(h.Cards)-len(held))
+	h.Cards = append(held, drawn...)
+	h.Deck = deck
+	h.DrawCount++
+	return len(drawn)
+}
+
+// final reports whether the hand has had its last draw
+func (h *pokerHand) final() bool {
+	return h.DrawCount >= maxDraws
+}
+
+// setHeld marks a card of the hand as held or not and reports whether the card is in the hand
+func (h *pokerHand) setHeld(cardID string, held bool) bool {
+	for i, card := range h.Cards {
+		if card.ID == cardID {
+			h.Cards[i].Held = held
+			return true
+		}
+	}
+	return false
+}
+
+// cardPayload identifies a card of the player's hand
+type cardPayload struct {
+	CardID string `json:"cardId"`
+}
+
+// Validate checks that a card is given
+func (p cardPayload) Validate() error {
+	if p.CardID == "" {
+		return errors.New("cardId is required")
+	}
+	return nil
+}
+
+// drawCards deals or draws the requesting player's hand and sends it to them alone, telling the rest
+// of the room how many cards were drawn. With stand, every card is held and the hand goes straight
+// to its final draw. The first hand ends the setup phase, and the final draw pays out gold.
+func drawCards(req *Request, stand bool) error {
+	playerID := req.PlayerID()
+	state := req.State()
+	state.Mutex.Lock()
+
+	// Each player plays one hand per round
+	if state.handsPlayed[playerID] {
+		state.Mutex.Unlock()
+		return withCode("hand_played", ErrHandPlayed)
+	}
+
+	var entered map[string]interface{}
+	if state.Phase == game.SetupPhase {
+		entered, _ = state.transition(game.CardsPhase)
+	}
+
+	// Dealing registers the player, so the room waits for their hand before building starts
+	player := state.Player(playerID)
+	hand := state.hand(playerID)
+	if stand {
+		if hand.DrawCount == 0 {
+			state.Mutex.Unlock()
+			return withCode("no_hand", ErrNoHand)
+		}
+		for i := range hand.Cards {
+			hand.Cards[i].Held = true
+		}
+		hand.DrawCount = maxDraws - 1
+	}
+
+	drawn := hand.draw()
+	handRank := game.EvaluateHand(hand.Cards)
+	payload := map[string]interface{}{
+		"cards":     append([]models.Card(nil), hand.Cards...),
+		"handRank":  handRank,
+		"drawCount": hand.DrawCount,
+		"maxDraws":  maxDraws,
+	}
+
+	// The final draw pays out gold, and building starts once every player has played their hand
+	var building map[string]interface{}
+	if hand.final() {
+		goldEarned := game.GoldForHand(handRank.Value)
+		player.Gold += goldEarned
+		if state.Director != nil {
+			state.Director.RecordHand(playerID, handRank.Value)
+		}
+		payload["goldEarned"] = goldEarned
+
+		if state.handPlayed(playerID) {
+			building, _ = state.transition(game.TowersPhase)
+		}
+	}
+	drawCount := hand.DrawCount
+	state.Mutex.Unlock()
+
+	if entered != nil {
+		req.Hub.phaseChanged(state, entered)
+	}
+
+	log.Printf("Player %s drew %d cards: %s (draw %d of %d)", playerID, drawn, handRank.Name, drawCount, maxDraws)
+
+	if err := req.Reply("cards_dealt", payload); err != nil {
+		return err
+	}
+	req.Hub.broadcastDraw(req.Client, drawn, drawCount)
+
+	if building != nil {
+		req.Hub.phaseChanged(state, building)
+	}
+	return nil
+}
+
+// setCardHeld holds or releases a card of the requesting player's hand and confirms it to the player alone
+func setCardHeld(req *Request, cardID string, held bool) error {
+	state := req.State()
+	state.Mutex.Lock()
+	found := state.hand(req.PlayerID()).setHeld(cardID, held)
+	state.Mutex.Unlock()
+
+	if !found {
+		return fmt.Errorf("%w: %s", ErrCardNotInHand, cardID)
+	}
+
+	// Held cards are private
+	req.Hub.Unicast(req.Client, req.Msg)
+	return nil
+}
@@ -0,0 +1,32 @@
+package ws
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+func init() {
+	register("buy_scouting", handleBuyScouting, authenticated, inRoom, inPhase, rateLimited(2, time.Second))
+}
+
+// handleBuyScouting reveals more upcoming waves to the player for gold
+func handleBuyScouting(req *Request, _ noPayload) error {
+	playerID := req.PlayerID()
+	state := req.State()
+	state.Mutex.Lock()
+	player := state.Player(playerID)
+	if player.Gold < scoutingCost {
+		state.Mutex.Unlock()
+		return fmt.Errorf("%w for scouting (%d gold)", ErrNotEnoughGold, player.Gold)
+	}
+	player.Gold -= scoutingCost
+	state.Scouting[playerID] = max(state.Scouting[playerID], state.WaveLevel) + scoutingWaves
+	preview := state.previewPayload(playerID, scoutingWaves)
+	state.Mutex.Unlock()
+
+	log.Printf("Player %s bought scouting for room %s", playerID, req.Msg.RoomID)
+
+	// Reveal the scouted waves right away
+	return req.Reply("wave_preview", preview)
+}
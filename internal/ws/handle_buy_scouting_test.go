@@ -0,0 +1,35 @@
+package ws
+
+import (
+	"testing"
+
+	"realtime-game-backend/internal/game"
+)
+
+func TestBuyScouting(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.TowersPhase)
+
+	sendMessage(t, client, "buy_scouting", nil)
+	expectMessage(t, client, "wave_preview")
+
+	state := hub.GetRoomState("room")
+	state.Mutex.Lock()
+	gold, scouted := state.Players["alice"].Gold, state.Scouting["alice"]
+	state.Mutex.Unlock()
+	if gold != startingGold-scoutingCost || scouted != scoutingWaves {
+		t.Fatalf("got %d gold and scouting through wave %d", gold, scouted)
+	}
+}
+
+func TestBuyScoutingNeedsGold(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.TowersPhase)
+
+	setGold(hub, "room", "alice", scoutingCost-1)
+
+	sendMessage(t, client, "buy_scouting", nil)
+	expectError(t, client, "insufficient_gold")
+}
@@ -0,0 +1,12 @@
+package ws
+
+import "time"
+
+func init() {
+	register("deal_cards", handleDealCards, authenticated, inRoom, inPhase, rateLimited(10, time.Second))
+}
+
+// handleDealCards deals the player a new poker hand, or draws replacements for the cards they don't hold
+func handleDealCards(req *Request, _ noPayload) error {
+	return drawCards(req, false)
+}
@@ -0,0 +1,90 @@
+package ws
+
+import (
+	"testing"
+
+	"realtime-game-backend/internal/game"
+	"realtime-game-backend/internal/models"
+)
+
+// dealtPayload is the payload of cards_dealt
+type dealtPayload struct {
+	Cards      []models.Card `json:"cards"`
+	DrawCount  int           `json:"drawCount"`
+	GoldEarned *int          `json:"goldEarned"`
+}
+
+func TestDealCardsDealsPrivately(t *testing.T) {
+	hub := newTestHub(t)
+	alice := joinTestClient(t, hub, "alice", "room")
+	bob := joinTestClient(t, hub, "bob", "room")
+
+	sendMessage(t, alice, "deal_cards", nil)
+
+	var phase struct {
+		Phase string `json:"phase"`
+	}
+	decodePayload(t, expectMessage(t, bob, "phase_changed"), &phase)
+	if phase.Phase != game.CardsPhase {
+		t.Fatalf("got phase %q, want %q", phase.Phase, game.CardsPhase)
+	}
+
+	var dealt dealtPayload
+	decodePayload(t, expectMessage(t, alice, "cards_dealt"), &dealt)
+	if len(dealt.Cards) != handSize || dealt.DrawCount != 1 || dealt.GoldEarned != nil {
+		t.Fatalf("got %d cards on draw %d, want a fresh hand of %d", len(dealt.Cards), dealt.DrawCount, handSize)
+	}
+
+	var drew struct {
+		PlayerID   string `json:"playerId"`
+		CardsDrawn int    `json:"cardsDrawn"`
+	}
+	decodePayload(t, expectMessage(t, bob, "player_drew"), &drew)
+	if drew.PlayerID != "alice" || drew.CardsDrawn != handSize {
+		t.Fatalf("got player_drew %+v", drew)
+	}
+	expectNoMessage(t, bob, "cards_dealt")
+}
+
+func TestDealCardsPaysOnFinalDraw(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	var dealt dealtPayload
+	for draw := 1; draw <= maxDraws; draw++ {
+		sendMessage(t, client, "deal_cards", nil)
+		decodePayload(t, expectMessage(t, client, "cards_dealt"), &dealt)
+	}
+	if dealt.DrawCount != maxDraws || dealt.GoldEarned == nil {
+		t.Fatalf("final draw %d paid %v", dealt.DrawCount, dealt.GoldEarned)
+	}
+
+	state := hub.GetRoomState("room")
+	state.Mutex.Lock()
+	gold, phase := state.Players["alice"].Gold, state.Phase
+	state.Mutex.Unlock()
+	if gold != startingGold+*dealt.GoldEarned {
+		t.Fatalf("got %d gold, want %d", gold, startingGold+*dealt.GoldEarned)
+	}
+	if phase != game.TowersPhase {
+		t.Fatalf("got phase %q after the only hand was played, want %q", phase, game.TowersPhase)
+	}
+}
+
+func TestDealCardsRejectsSecondHand(t *testing.T) {
+	hub := newTestHub(t)
+	alice := joinTestClient(t, hub, "alice", "room")
+	bob := joinTestClient(t, hub, "bob", "room")
+
+	// Bob's unfinished hand keeps the room in the cards phase
+	sendMessage(t, bob, "deal_cards", nil)
+	expectMessage(t, bob, "cards_dealt")
+
+	for draw := 1; draw <= maxDraws; draw++ {
+		sendMessage(t, alice, "deal_cards", nil)
+		expectMessage(t, alice, "cards_dealt")
+	}
+
+	sendMessage(t, alice, "deal_cards", nil)
+	expectError(t, alice, "hand_played")
+}
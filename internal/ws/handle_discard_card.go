@@ -0,0 +1,12 @@
+package ws
+
+import "time"
+
+func init() {
+	register("discard_card", handleDiscardCard, authenticated, inRoom, inPhase, rateLimited(20, time.Second))
+}
+
+// handleDiscardCard releases a held card so the next draw replaces it
+func handleDiscardCard(req *Request, payload cardPayload) error {
+	return setCardHeld(req, payload.CardID, false)
+}
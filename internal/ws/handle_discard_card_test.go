@@ -0,0 +1,33 @@
+package ws
+
+import "testing"
+
+func TestDiscardCard(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	var dealt dealtPayload
+	sendMessage(t, client, "deal_cards", nil)
+	decodePayload(t, expectMessage(t, client, "cards_dealt"), &dealt)
+
+	cardID := dealt.Cards[0].ID
+	sendMessage(t, client, "hold_card", cardPayload{CardID: cardID})
+	expectMessage(t, client, "hold_card")
+	sendMessage(t, client, "discard_card", cardPayload{CardID: cardID})
+	expectMessage(t, client, "discard_card")
+
+	if heldCards(hub, "room", "alice")[cardID] {
+		t.Fatalf("card %s is still held", cardID)
+	}
+}
+
+func TestDiscardCardOutsideHand(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	sendMessage(t, client, "deal_cards", nil)
+	expectMessage(t, client, "cards_dealt")
+
+	sendMessage(t, client, "discard_card", cardPayload{CardID: "missing"})
+	expectError(t, client, "card_not_in_hand")
+}
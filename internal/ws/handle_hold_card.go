@@ -0,0 +1,12 @@
+package ws
+
+import "time"
+
+func init() {
+	register("hold_card", handleHoldCard, authenticated, inRoom, inPhase, rateLimited(20, time.Second))
+}
+
+// handleHoldCard holds a card so the next draw keeps it
+func handleHoldCard(req *Request, payload cardPayload) error {
+	return setCardHeld(req, payload.CardID, true)
+}
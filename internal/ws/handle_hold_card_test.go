@@ -0,0 +1,46 @@
+package ws
+
+import "testing"
+
+// heldCards returns which cards of a player's hand are held
+func heldCards(hub *Hub, roomID, playerID string) map[string]bool {
+	state := hub.GetRoomState(roomID)
+	state.Mutex.Lock()
+	defer state.Mutex.Unlock()
+
+	held := make(map[string]bool)
+	for _, card := range state.hand(playerID).Cards {
+		held[card.ID] = card.Held
+	}
+	return held
+}
+
+func TestHoldCard(t *testing.T) {
+	hub := newTestHub(t)
+	alice := joinTestClient(t, hub, "alice", "room")
+	bob := joinTestClient(t, hub, "bob", "room")
+
+	var dealt dealtPayload
+	sendMessage(t, alice, "deal_cards", nil)
+	decodePayload(t, expectMessage(t, alice, "cards_dealt"), &dealt)
+
+	cardID := dealt.Cards[0].ID
+	sendMessage(t, alice, "hold_card", cardPayload{CardID: cardID})
+	expectMessage(t, alice, "hold_card")
+	expectNoMessage(t, bob, "hold_card")
+
+	if !heldCards(hub, "room", "alice")[cardID] {
+		t.Fatalf("card %s isn't held", cardID)
+	}
+}
+
+func TestHoldCardNeedsCardID(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	sendMessage(t, client, "deal_cards", nil)
+	expectMessage(t, client, "cards_dealt")
+
+	sendMessage(t, client, "hold_card", cardPayload{})
+	expectError(t, client, "invalid_payload")
+}
@@ -0,0 +1,12 @@
+package ws
+
+import "time"
+
+func init() {
+	register("hold_hand", handleHoldHand, authenticated, inRoom, inPhase, rateLimited(10, time.Second))
+}
+
+// handleHoldHand keeps the player's whole hand and skips to the final draw, paying out its gold
+func handleHoldHand(req *Request, _ noPayload) error {
+	return drawCards(req, true)
+}
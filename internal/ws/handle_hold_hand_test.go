@@ -0,0 +1,38 @@
+package ws
+
+import "testing"
+
+func TestHoldHandKeepsCardsAndPays(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	var first dealtPayload
+	sendMessage(t, client, "deal_cards", nil)
+	decodePayload(t, expectMessage(t, client, "cards_dealt"), &first)
+
+	var final dealtPayload
+	sendMessage(t, client, "hold_hand", nil)
+	decodePayload(t, expectMessage(t, client, "cards_dealt"), &final)
+
+	if final.DrawCount != maxDraws || final.GoldEarned == nil {
+		t.Fatalf("hold_hand ended on draw %d paying %v", final.DrawCount, final.GoldEarned)
+	}
+	for i, card := range final.Cards {
+		if card.ID != first.Cards[i].ID || !card.Held {
+			t.Fatalf("card %d changed from %+v to %+v", i, first.Cards[i], card)
+		}
+	}
+}
+
+func TestHoldHandNeedsAHand(t *testing.T) {
+	hub := newTestHub(t)
+	alice := joinTestClient(t, hub, "alice", "room")
+	bob := joinTestClient(t, hub, "bob", "room")
+
+	// Alice's first hand moves the room to the cards phase before Bob has one
+	sendMessage(t, alice, "deal_cards", nil)
+	expectMessage(t, bob, "phase_changed")
+
+	sendMessage(t, bob, "hold_hand", nil)
+	expectError(t, bob, "no_hand")
+}
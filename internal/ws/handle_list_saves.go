@@ -0,0 +1,19 @@
+package ws
+
+import "time"
+
+func init() {
+	register("list_saves", handleListSaves, authenticated, rateLimited(2, time.Second))
+}
+
+// handleListSaves sends the player's saved games
+func handleListSaves(req *Request, _ noPayload) error {
+	saves, err := req.Hub.savedGames(req.Client)
+	if err != nil {
+		return err
+	}
+
+	return req.Reply("saved_games", map[string]interface{}{
+		"saves": saves,
+	})
+}
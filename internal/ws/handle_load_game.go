@@ -0,0 +1,45 @@
+package ws
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+func init() {
+	register("load_game", handleLoadGame, authenticated, rateLimited(1, 5*time.Second))
+}
+
+// loadGamePayload is the payload of load_game
+type loadGamePayload struct {
+	SaveID string `json:"saveId"`
+}
+
+// Validate checks that a save is given
+func (p loadGamePayload) Validate() error {
+	if p.SaveID == "" {
+		return errors.New("saveId is required")
+	}
+	return nil
+}
+
+// handleLoadGame restores one of the player's saved games into a new room and moves them there
+func handleLoadGame(req *Request, payload loadGamePayload) error {
+	state, err := req.Hub.loadGame(req.Client, payload.SaveID)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Player %s loaded game %s into room %s", req.PlayerID(), payload.SaveID, state.ID)
+
+	// Send the restored game, including the player's hand
+	state.Mutex.Lock()
+	loadedPayload := map[string]interface{}{
+		"saveId": payload.SaveID,
+		"roomId": state.ID,
+		"state":  state.snapshot(req.PlayerID()),
+	}
+	state.Mutex.Unlock()
+
+	return req.Reply("game_loaded", loadedPayload)
+}
@@ -0,0 +1,21 @@
+package ws
+
+import "testing"
+
+func TestLoadGameNeedsSaveID(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "")
+
+	sendMessage(t, client, "load_game", loadGamePayload{})
+	expectError(t, client, "invalid_payload")
+}
+
+func TestLoadGameNeedsPersistence(t *testing.T) {
+	hub := newTestHub(t)
+
+	// Saved games can be loaded from outside a room
+	client := joinTestClient(t, hub, "alice", "")
+
+	sendMessage(t, client, "load_game", loadGamePayload{SaveID: "save"})
+	expectError(t, client, "saves_unavailable")
+}
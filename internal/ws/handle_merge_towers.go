@@ -0,0 +1,96 @@
+package ws
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"realtime-game-backend/internal/game"
+	"realtime-game-backend/internal/models"
+)
+
+func init() {
+	register("merge_towers", handleMergeTowers, authenticated, inRoom, inPhase, rateLimited(10, time.Second))
+}
+
+// mergeTowersPayload is the payload of merge_towers
+type mergeTowersPayload struct {
+	TowerIDs []string `json:"towerIds"`
+}
+
+// Validate checks that towers are given
+func (p mergeTowersPayload) Validate() error {
+	if len(p.TowerIDs) == 0 {
+		return errors.New("towerIds is required")
+	}
+	return nil
+}
+
+// handleMergeTowers merges adjacent towers of the same type and level into one tower of the next level
+func handleMergeTowers(req *Request, payload mergeTowersPayload) error {
+	playerID := req.PlayerID()
+	log.Printf("Player %s is merging towers %v", playerID, payload.TowerIDs)
+
+	// Find the towers in the room, in the order they were given
+	state := req.State()
+	state.Mutex.Lock()
+	var towers []models.Tower
+	for _, towerID := range payload.TowerIDs {
+		for _, existing := range state.Towers {
+			if existing.ID == towerID {
+				towers = append(towers, existing)
+				break
+			}
+		}
+	}
+
+	if len(towers) != len(payload.TowerIDs) {
+		state.Mutex.Unlock()
+		return fmt.Errorf("%w: some of %v", ErrTowerNotFound, payload.TowerIDs)
+	}
+
+	// Validate ownership, type, level and adjacency
+	merged, err := game.MergeTowers(playerID, towers)
+	if err != nil {
+		state.Mutex.Unlock()
+		return err
+	}
+
+	// Replace the merged towers with the new tower
+	remaining := state.Towers[:0]
+	for _, existing := range state.Towers {
+		mergedAway := false
+		for _, tower := range towers {
+			if existing.ID == tower.ID {
+				mergedAway = true
+				break
+			}
+		}
+		if !mergedAway {
+			remaining = append(remaining, existing)
+		}
+	}
+	state.Towers = append(remaining, merged)
+
+	if state.Simulation != nil {
+		for _, tower := range towers {
+			state.Simulation.RemoveTower(tower.ID)
+		}
+		state.Simulation.AddTower(merged)
+	}
+	state.Mutex.Unlock()
+
+	log.Printf("Sending towers_merged response to room %s", req.Msg.RoomID)
+
+	if err := req.Broadcast("towers_merged", map[string]interface{}{
+		"mergedTowerIds": payload.TowerIDs,
+		"tower":          merged,
+	}); err != nil {
+		return err
+	}
+
+	// Merging frees tiles, which can open shorter routes
+	req.Hub.broadcastMazePaths(state)
+	return nil
+}
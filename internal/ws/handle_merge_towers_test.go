@@ -0,0 +1,57 @@
+package ws
+
+import (
+	"testing"
+
+	"realtime-game-backend/internal/game"
+	"realtime-game-backend/internal/models"
+)
+
+func TestMergeTowers(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.TowersPhase)
+
+	// Two towers side by side on the same tile
+	setGold(hub, "room", "alice", 1000)
+	x, y := buildableSpot(t, hub, "room")
+	first := placeTower(t, client, x-20, y)
+	second := placeTower(t, client, x+20, y)
+
+	sendMessage(t, client, "merge_towers", mergeTowersPayload{TowerIDs: []string{first.ID, second.ID}})
+
+	var merged struct {
+		MergedTowerIDs []string     `json:"mergedTowerIds"`
+		Tower          models.Tower `json:"tower"`
+	}
+	decodePayload(t, expectMessage(t, client, "towers_merged"), &merged)
+	if len(merged.MergedTowerIDs) != 2 || merged.Tower.Level != first.Level+1 {
+		t.Fatalf("got merge %+v", merged)
+	}
+
+	state := hub.GetRoomState("room")
+	state.Mutex.Lock()
+	towers := len(state.Towers)
+	state.Mutex.Unlock()
+	if towers != 1 {
+		t.Fatalf("got %d towers after merging, want 1", towers)
+	}
+}
+
+func TestMergeUnknownTowers(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.TowersPhase)
+
+	sendMessage(t, client, "merge_towers", mergeTowersPayload{TowerIDs: []string{"a", "b"}})
+	expectError(t, client, "tower_not_found")
+}
+
+func TestMergeTowersNeedsTowers(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.TowersPhase)
+
+	sendMessage(t, client, "merge_towers", mergeTowersPayload{})
+	expectError(t, client, "invalid_payload")
+}
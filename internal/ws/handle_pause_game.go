@@ -0,0 +1,12 @@
+package ws
+
+import "time"
+
+func init() {
+	register("pause_game", handlePauseGame, authenticated, inRoom, rateLimited(2, time.Second))
+}
+
+// handlePauseGame asks to pause the game, or votes for a pending pause
+func handlePauseGame(req *Request, _ noPayload) error {
+	return req.Hub.votePause(req.State(), req.PlayerID())
+}
@@ -0,0 +1,91 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"realtime-game-backend/internal/game"
+)
+
+func TestPauseGame(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	sendMessage(t, client, "pause_game", nil)
+
+	var paused struct {
+		RequestedBy string `json:"requestedBy"`
+		PausesLeft  int    `json:"pausesLeft"`
+	}
+	decodePayload(t, expectMessage(t, client, "game_paused"), &paused)
+	if paused.RequestedBy != "alice" || paused.PausesLeft != maxPausesPerPlayer-1 {
+		t.Fatalf("got %+v", paused)
+	}
+
+	sendMessage(t, client, "pause_game", nil)
+	expectError(t, client, "game_paused")
+}
+
+func TestPauseGameNeedsMajority(t *testing.T) {
+	hub := newTestHub(t)
+	alice := joinTestClient(t, hub, "alice", "room")
+	joinTestClient(t, hub, "bob", "room")
+
+	sendMessage(t, alice, "pause_game", nil)
+	expectMessage(t, alice, "pause_vote")
+	expectNoMessage(t, alice, "game_paused")
+}
+
+func TestPausedWaveStopsWhenRoomEmpties(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.TowersPhase)
+	state := hub.GetRoomState("room")
+
+	sendMessage(t, client, "start_wave", nil)
+	expectMessage(t, client, "wave_started")
+	sendMessage(t, client, "pause_game", nil)
+	expectMessage(t, client, "game_paused")
+
+	hub.Unregister <- client
+	waitForSimulationEnd(t, state)
+}
+
+// waitForSimulationEnd waits for a room's wave simulation to stop
+func waitForSimulationEnd(t *testing.T, state *RoomState) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		state.Mutex.Lock()
+		running := state.Simulation != nil
+		state.Mutex.Unlock()
+		if !running {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("wave kept running after the room was dropped")
+}
+
+func TestAutoResumeStopsWhenRoomIsDropped(t *testing.T) {
+	hub := newTestHub(t)
+	joinTestClient(t, hub, "alice", "room")
+	state := hub.GetRoomState("room")
+
+	done := make(chan struct{})
+	go func() {
+		hub.autoResume(state, time.Now())
+		close(done)
+	}()
+
+	hub.Mutex.Lock()
+	hub.dropRoomState("room")
+	hub.Mutex.Unlock()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("auto-resume kept waiting after the room was dropped")
+	}
+}
@@ -0,0 +1,81 @@
+package ws
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"realtime-game-backend/internal/game"
+)
+
+func init() {
+	register("place_tower", handlePlaceTower, authenticated, inRoom, inPhase, rateLimited(10, time.Second))
+}
+
+// placeTowerPayload is the payload of place_tower
+type placeTowerPayload struct {
+	TowerType string  `json:"towerType"`
+	X         float64 `json:"x"`
+	Y         float64 `json:"y"`
+}
+
+// Validate checks that a tower type is given
+func (p placeTowerPayload) Validate() error {
+	if p.TowerType == "" {
+		return errors.New("towerType is required")
+	}
+	return nil
+}
+
+// handlePlaceTower builds a tower from the tower catalog if its owner can afford it
+func handlePlaceTower(req *Request, payload placeTowerPayload) error {
+	playerID := req.PlayerID()
+	log.Printf("Player %s is placing a %s tower at (%.1f, %.1f)", playerID, payload.TowerType, payload.X, payload.Y)
+
+	tower, err := game.CreateTower(playerID, payload.TowerType, payload.X, payload.Y)
+	if err != nil {
+		return err
+	}
+
+	// Add the tower to the room and charge its owner
+	state := req.State()
+	state.Mutex.Lock()
+	if state.Mode == game.MazeMode {
+		// Maze towers fill whole tiles and must leave a route to the base
+		err = game.CheckMazePlacement(state.Map, state.Towers, tower.X, tower.Y)
+		if err == nil {
+			center, _ := state.Map.SnapToTile(tower.X, tower.Y)
+			tower.X, tower.Y = center.X, center.Y
+		}
+	} else {
+		err = state.Map.CheckBuildable(tower.X, tower.Y)
+	}
+	if err != nil {
+		state.Mutex.Unlock()
+		return fmt.Errorf("placing at (%.1f, %.1f) on map %s: %w", tower.X, tower.Y, state.Map.ID, err)
+	}
+	player := state.Player(playerID)
+	if player.Gold < tower.Cost {
+		state.Mutex.Unlock()
+		return fmt.Errorf("%w for a %s tower (%d of %d gold)", ErrNotEnoughGold, tower.Type, player.Gold, tower.Cost)
+	}
+	state.Towers = append(state.Towers, tower)
+	if state.Simulation != nil {
+		state.Simulation.AddTower(tower)
+	}
+	player.Gold -= tower.Cost
+	state.Mutex.Unlock()
+
+	log.Printf("Sending tower_placed response to room %s", req.Msg.RoomID)
+
+	if err := req.Broadcast("tower_placed", map[string]interface{}{
+		"tower": tower,
+	}); err != nil {
+		return err
+	}
+
+	// Let clients redraw the routes around the new tower
+	req.Hub.broadcastMazePaths(state)
+	return nil
+}
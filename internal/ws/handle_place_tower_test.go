@@ -0,0 +1,92 @@
+package ws
+
+import (
+	"testing"
+
+	"realtime-game-backend/internal/game"
+	"realtime-game-backend/internal/models"
+)
+
+// buildableSpot returns the center of a tile of the room's map that towers can be built on
+func buildableSpot(t *testing.T, hub *Hub, roomID string) (float64, float64) {
+	t.Helper()
+
+	state := hub.GetRoomState(roomID)
+	state.Mutex.Lock()
+	defer state.Mutex.Unlock()
+
+	m := state.Map
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			cx, cy := (float64(x)+0.5)*m.TileSize, (float64(y)+0.5)*m.TileSize
+			if m.CheckBuildable(cx, cy) == nil {
+				return cx, cy
+			}
+		}
+	}
+	t.Fatalf("map %s has no buildable tile", m.ID)
+	return 0, 0
+}
+
+// placeTower places a basic tower and returns it as the room saw it
+func placeTower(t *testing.T, client *Client, x, y float64) models.Tower {
+	t.Helper()
+
+	sendMessage(t, client, "place_tower", placeTowerPayload{TowerType: "basic", X: x, Y: y})
+
+	var placed struct {
+		Tower models.Tower `json:"tower"`
+	}
+	decodePayload(t, expectMessage(t, client, "tower_placed"), &placed)
+	return placed.Tower
+}
+
+func TestPlaceTower(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.TowersPhase)
+
+	x, y := buildableSpot(t, hub, "room")
+	tower := placeTower(t, client, x, y)
+	if tower.PlayerID != "alice" || tower.Type != "basic" {
+		t.Fatalf("got tower %+v", tower)
+	}
+
+	state := hub.GetRoomState("room")
+	state.Mutex.Lock()
+	towers, gold := len(state.Towers), state.Players["alice"].Gold
+	state.Mutex.Unlock()
+	if towers != 1 || gold != startingGold-tower.Cost {
+		t.Fatalf("got %d towers and %d gold", towers, gold)
+	}
+}
+
+func TestPlaceTowerNeedsGold(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.TowersPhase)
+	setGold(hub, "room", "alice", 0)
+
+	x, y := buildableSpot(t, hub, "room")
+	sendMessage(t, client, "place_tower", placeTowerPayload{TowerType: "basic", X: x, Y: y})
+	expectError(t, client, "insufficient_gold")
+}
+
+func TestPlaceUnknownTower(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.TowersPhase)
+
+	x, y := buildableSpot(t, hub, "room")
+	sendMessage(t, client, "place_tower", placeTowerPayload{TowerType: "laser", X: x, Y: y})
+	expectError(t, client, "unknown_tower")
+}
+
+func TestPlaceTowerNeedsType(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.TowersPhase)
+
+	sendMessage(t, client, "place_tower", placeTowerPayload{X: 150, Y: 150})
+	expectError(t, client, "invalid_payload")
+}
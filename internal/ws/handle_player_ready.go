@@ -0,0 +1,33 @@
+package ws
+
+import (
+	"log"
+	"time"
+)
+
+func init() {
+	register("player_ready", handlePlayerReady, authenticated, inRoom, inPhase, rateLimited(2, time.Second))
+}
+
+// handlePlayerReady marks the player ready, moving the room on once everyone is
+func handlePlayerReady(req *Request, _ noPayload) error {
+	playerID := req.PlayerID()
+	state := req.State()
+	state.Mutex.Lock()
+	seq := state.phaseSeq
+	state.Mutex.Unlock()
+
+	ready, allReady, err := req.Hub.setPlayerReady(state, playerID)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Player %s is ready in room %s (%d ready)", playerID, req.Msg.RoomID, len(ready))
+
+	req.Hub.broadcastReady(req.Msg.RoomID, playerID, ready, allReady)
+
+	if allReady {
+		req.Hub.advancePhase(state, seq, playerID)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package ws
+
+import (
+	"testing"
+
+	"realtime-game-backend/internal/game"
+)
+
+func TestPlayerReadyWaitsForEveryone(t *testing.T) {
+	hub := newTestHub(t)
+	alice := joinTestClient(t, hub, "alice", "room")
+	bob := joinTestClient(t, hub, "bob", "room")
+
+	var ready struct {
+		ReadyPlayers []string `json:"readyPlayers"`
+		AllReady     bool     `json:"allReady"`
+	}
+	sendMessage(t, alice, "player_ready", nil)
+	decodePayload(t, expectMessage(t, bob, "ready_changed"), &ready)
+	if ready.AllReady || len(ready.ReadyPlayers) != 1 {
+		t.Fatalf("got %+v after one of two players was ready", ready)
+	}
+
+	sendMessage(t, bob, "player_ready", nil)
+	decodePayload(t, expectMessage(t, bob, "ready_changed"), &ready)
+	if !ready.AllReady {
+		t.Fatalf("got %+v after both players were ready", ready)
+	}
+
+	var phase struct {
+		Phase string `json:"phase"`
+	}
+	decodePayload(t, expectMessage(t, bob, "phase_changed"), &phase)
+	if phase.Phase != game.CardsPhase {
+		t.Fatalf("got phase %q, want %q", phase.Phase, game.CardsPhase)
+	}
+}
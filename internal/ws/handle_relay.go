@@ -0,0 +1,23 @@
+package ws
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// relayedTypes are client messages passed on to the rest of the room as they are.
+// Every other message type needs a handler, so clients can't forge server events.
+var relayedTypes = []string{"join_room", "leave_room", "ready"}
+
+func init() {
+	for _, msgType := range relayedTypes {
+		register(msgType, relay, authenticated, inRoom, rateLimited(5, time.Second))
+	}
+}
+
+// relay passes a message on to the sender's room, stamped with the sender's player ID
+func relay(req *Request, _ json.RawMessage) error {
+	req.Msg.SenderID = req.Client.PlayerID
+	req.Hub.BroadcastToRoom(req.Client.RoomID, req.Msg)
+	return nil
+}
@@ -0,0 +1,12 @@
+package ws
+
+import "time"
+
+func init() {
+	register("resume_game", handleResumeGame, authenticated, inRoom, rateLimited(2, time.Second))
+}
+
+// handleResumeGame resumes the paused game
+func handleResumeGame(req *Request, _ noPayload) error {
+	return req.Hub.resumeGame(req.State(), req.PlayerID())
+}
@@ -0,0 +1,29 @@
+package ws
+
+import "testing"
+
+func TestResumeGame(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	sendMessage(t, client, "pause_game", nil)
+	expectMessage(t, client, "game_paused")
+
+	sendMessage(t, client, "resume_game", nil)
+
+	var resumed struct {
+		PlayerID string `json:"playerId"`
+	}
+	decodePayload(t, expectMessage(t, client, "game_resumed"), &resumed)
+	if resumed.PlayerID != "alice" {
+		t.Fatalf("game resumed by %q, want alice", resumed.PlayerID)
+	}
+}
+
+func TestResumeGameNotPaused(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	sendMessage(t, client, "resume_game", nil)
+	expectError(t, client, "not_paused")
+}
@@ -0,0 +1,28 @@
+package ws
+
+import (
+	"log"
+	"time"
+)
+
+func init() {
+	register("save_game", handleSaveGame, authenticated, inRoom, inPhase, rateLimited(1, 5*time.Second))
+}
+
+// handleSaveGame saves the player's solo game and replies with the save's ID
+func handleSaveGame(req *Request, _ noPayload) error {
+	saveID, snapshot, err := req.Hub.saveGame(req.Client)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Saved game %s of player %s at wave %d", saveID, req.PlayerID(), snapshot.WaveLevel)
+
+	return req.Reply("game_saved", map[string]interface{}{
+		"saveId":    saveID,
+		"runType":   snapshot.RunType,
+		"waveLevel": snapshot.WaveLevel,
+		"phase":     snapshot.Phase,
+		"round":     snapshot.Round,
+	})
+}
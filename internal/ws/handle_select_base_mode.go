@@ -0,0 +1,45 @@
+package ws
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+func init() {
+	register("select_base_mode", handleSelectBaseMode, authenticated, inRoom, inPhase, rateLimited(5, time.Second))
+}
+
+// selectBaseModePayload is the payload of select_base_mode
+type selectBaseModePayload struct {
+	BaseMode string `json:"baseMode"`
+}
+
+// Validate checks that the base mode is known
+func (p selectBaseModePayload) Validate() error {
+	if p.BaseMode != PlayerBase && p.BaseMode != SharedBase {
+		return fmt.Errorf("unknown base mode %q", p.BaseMode)
+	}
+	return nil
+}
+
+// handleSelectBaseMode switches the room between a base per player and a shared base
+func handleSelectBaseMode(req *Request, payload selectBaseModePayload) error {
+	// The base mode can only change before the game starts
+	state := req.State()
+	state.Mutex.Lock()
+	if state.SessionID != "" {
+		state.Mutex.Unlock()
+		return fmt.Errorf("%w: the base mode can't change after the first wave", ErrSettingsLocked)
+	}
+	state.BaseMode = payload.BaseMode
+	state.BaseHealth = startingHealth
+	state.Mutex.Unlock()
+
+	log.Printf("Player %s selected the %s base mode for room %s", req.PlayerID(), payload.BaseMode, req.Msg.RoomID)
+
+	return req.Broadcast("base_mode_selected", map[string]interface{}{
+		"baseMode": payload.BaseMode,
+		"health":   startingHealth,
+	})
+}
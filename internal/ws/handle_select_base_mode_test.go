@@ -0,0 +1,24 @@
+package ws
+
+import "testing"
+
+func TestSelectBaseMode(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	sendMessage(t, client, "select_base_mode", selectBaseModePayload{BaseMode: SharedBase})
+
+	var selected selectBaseModePayload
+	decodePayload(t, expectMessage(t, client, "base_mode_selected"), &selected)
+	if selected.BaseMode != SharedBase {
+		t.Fatalf("got base mode %q, want %q", selected.BaseMode, SharedBase)
+	}
+}
+
+func TestSelectBaseModeRejectsUnknownMode(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	sendMessage(t, client, "select_base_mode", selectBaseModePayload{BaseMode: "fortress"})
+	expectError(t, client, "invalid_payload")
+}
@@ -0,0 +1,44 @@
+package ws
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"realtime-game-backend/internal/game"
+)
+
+func init() {
+	register("select_difficulty", handleSelectDifficulty, authenticated, inRoom, inPhase, rateLimited(5, time.Second))
+}
+
+// selectDifficultyPayload is the payload of select_difficulty
+type selectDifficultyPayload struct {
+	Adaptive bool `json:"adaptive"`
+}
+
+// handleSelectDifficulty switches adaptive difficulty on or off for the room
+func handleSelectDifficulty(req *Request, payload selectDifficultyPayload) error {
+	// Adaptive difficulty can only be switched before the game starts
+	state := req.State()
+	state.Mutex.Lock()
+	if state.SessionID != "" {
+		state.Mutex.Unlock()
+		return fmt.Errorf("%w: the difficulty can't change after the first wave", ErrSettingsLocked)
+	}
+	state.Director = nil
+	if payload.Adaptive {
+		state.Director = game.NewDirector()
+	}
+	state.Mutex.Unlock()
+
+	log.Printf("Player %s set adaptive difficulty to %t for room %s", req.PlayerID(), payload.Adaptive, req.Msg.RoomID)
+
+	difficultyPayload := map[string]interface{}{
+		"adaptive": payload.Adaptive,
+	}
+	if payload.Adaptive {
+		difficultyPayload["bounds"] = game.GetDirectorConfig()
+	}
+	return req.Broadcast("difficulty_selected", difficultyPayload)
+}
@@ -0,0 +1,40 @@
+package ws
+
+import "testing"
+
+func TestSelectDifficulty(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	sendMessage(t, client, "select_difficulty", selectDifficultyPayload{Adaptive: true})
+
+	var selected struct {
+		Adaptive bool                   `json:"adaptive"`
+		Bounds   map[string]interface{} `json:"bounds"`
+	}
+	decodePayload(t, expectMessage(t, client, "difficulty_selected"), &selected)
+	if !selected.Adaptive || selected.Bounds == nil {
+		t.Fatalf("got %+v", selected)
+	}
+
+	state := hub.GetRoomState("room")
+	state.Mutex.Lock()
+	adaptive := state.Director != nil
+	state.Mutex.Unlock()
+	if !adaptive {
+		t.Fatal("room has no director")
+	}
+}
+
+func TestSelectDifficultyAfterFirstWave(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	state := hub.GetRoomState("room")
+	state.Mutex.Lock()
+	state.SessionID = "session"
+	state.Mutex.Unlock()
+
+	sendMessage(t, client, "select_difficulty", selectDifficultyPayload{Adaptive: true})
+	expectError(t, client, "settings_locked")
+}
@@ -0,0 +1,64 @@
+package ws
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"realtime-game-backend/internal/game"
+)
+
+func init() {
+	register("select_map", handleSelectMap, authenticated, inRoom, inPhase, rateLimited(5, time.Second))
+}
+
+// Error definitions
+var (
+	ErrSettingsLocked = errors.New("room settings are locked")
+)
+
+// selectMapPayload is the payload of select_map
+type selectMapPayload struct {
+	MapID string `json:"mapId"`
+	Mode  string `json:"mode"` // Defaults to lanes
+}
+
+// Validate checks that a map is given
+func (p selectMapPayload) Validate() error {
+	if p.MapID == "" {
+		return errors.New("mapId is required")
+	}
+	return nil
+}
+
+// handleSelectMap switches the room to another map and game mode
+func handleSelectMap(req *Request, payload selectMapPayload) error {
+	m, err := game.GetMap(payload.MapID)
+	if err != nil {
+		return err
+	}
+
+	if payload.Mode == "" {
+		payload.Mode = game.LanesMode
+	}
+	if err := game.ValidateMode(payload.Mode, m); err != nil {
+		return err
+	}
+
+	// The map can only change before anything is built or fought on it
+	state := req.State()
+	state.Mutex.Lock()
+	if state.Simulation != nil || len(state.Towers) > 0 {
+		state.Mutex.Unlock()
+		return fmt.Errorf("%w: the map can't change once towers are placed", ErrSettingsLocked)
+	}
+	state.Map = m
+	state.Mode = payload.Mode
+	mapInfo := state.mapPayload()
+	state.Mutex.Unlock()
+
+	log.Printf("Player %s selected map %s in %s mode for room %s", req.PlayerID(), m.ID, payload.Mode, req.Msg.RoomID)
+
+	return req.Broadcast("map_selected", mapInfo)
+}
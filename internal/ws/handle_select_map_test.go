@@ -0,0 +1,37 @@
+package ws
+
+import "testing"
+
+func TestSelectMap(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	sendMessage(t, client, "select_map", selectMapPayload{MapID: "arena"})
+
+	var selected struct {
+		Map struct {
+			ID string `json:"id"`
+		} `json:"map"`
+		Mode string `json:"mode"`
+	}
+	decodePayload(t, expectMessage(t, client, "map_selected"), &selected)
+	if selected.Map.ID != "arena" || selected.Mode != "lanes" {
+		t.Fatalf("got map %q in %q mode", selected.Map.ID, selected.Mode)
+	}
+}
+
+func TestSelectUnknownMap(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	sendMessage(t, client, "select_map", selectMapPayload{MapID: "atlantis"})
+	expectError(t, client, "unknown_map")
+}
+
+func TestSelectMapNeedsMapID(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	sendMessage(t, client, "select_map", selectMapPayload{})
+	expectError(t, client, "invalid_payload")
+}
@@ -0,0 +1,51 @@
+package ws
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"realtime-game-backend/internal/game"
+)
+
+func init() {
+	register("select_run_type", handleSelectRunType, authenticated, inRoom, inPhase, rateLimited(5, time.Second))
+}
+
+// selectRunTypePayload is the payload of select_run_type
+type selectRunTypePayload struct {
+	RunType string `json:"runType"`
+	Seed    int64  `json:"seed"` // Optional, a random seed is picked when omitted
+}
+
+// Validate checks that the run type is known
+func (p selectRunTypePayload) Validate() error {
+	if p.RunType != game.CampaignRun && p.RunType != game.EndlessRun {
+		return fmt.Errorf("unknown run type %q", p.RunType)
+	}
+	return nil
+}
+
+// handleSelectRunType switches the room between a campaign and an endless run
+func handleSelectRunType(req *Request, payload selectRunTypePayload) error {
+	if payload.Seed == 0 {
+		payload.Seed = time.Now().UnixNano()
+	}
+
+	// The run type can only change before the first wave
+	state := req.State()
+	state.Mutex.Lock()
+	if state.Simulation != nil || state.WaveLevel > 0 {
+		state.Mutex.Unlock()
+		return fmt.Errorf("%w: the run type can't change after the first wave", ErrSettingsLocked)
+	}
+	state.SetRunType(payload.RunType, payload.Seed)
+	state.Mutex.Unlock()
+
+	log.Printf("Player %s selected a %s run with seed %d for room %s", req.PlayerID(), payload.RunType, payload.Seed, req.Msg.RoomID)
+
+	return req.Broadcast("run_type_selected", map[string]interface{}{
+		"runType": payload.RunType,
+		"seed":    payload.Seed,
+	})
+}
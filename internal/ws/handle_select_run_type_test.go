@@ -0,0 +1,28 @@
+package ws
+
+import (
+	"testing"
+
+	"realtime-game-backend/internal/game"
+)
+
+func TestSelectRunType(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	sendMessage(t, client, "select_run_type", selectRunTypePayload{RunType: game.EndlessRun, Seed: 42})
+
+	var selected selectRunTypePayload
+	decodePayload(t, expectMessage(t, client, "run_type_selected"), &selected)
+	if selected.RunType != game.EndlessRun || selected.Seed != 42 {
+		t.Fatalf("got %+v", selected)
+	}
+}
+
+func TestSelectRunTypeRejectsUnknownType(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	sendMessage(t, client, "select_run_type", selectRunTypePayload{RunType: "sprint"})
+	expectError(t, client, "invalid_payload")
+}
@@ -0,0 +1,17 @@
+package ws
+
+import (
+	"log"
+	"time"
+)
+
+func init() {
+	register("start_wave", handleStartWave, authenticated, inRoom, inPhase, rateLimited(2, time.Second))
+}
+
+// handleStartWave starts the next wave against the player's base
+func handleStartWave(req *Request, _ noPayload) error {
+	log.Printf("Handling start_wave message from %s", req.PlayerID())
+
+	return req.Hub.startWave(req.State(), req.PlayerID())
+}
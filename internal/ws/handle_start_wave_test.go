@@ -0,0 +1,170 @@
+package ws
+
+import (
+	"testing"
+
+	"realtime-game-backend/internal/game"
+	"realtime-game-backend/internal/models"
+)
+
+func TestStartWave(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.TowersPhase)
+
+	sendMessage(t, client, "start_wave", nil)
+
+	var started struct {
+		PlayerID string `json:"playerId"`
+	}
+	decodePayload(t, expectMessage(t, client, "wave_started"), &started)
+	if started.PlayerID != "alice" {
+		t.Fatalf("wave started by %q, want alice", started.PlayerID)
+	}
+}
+
+func TestStartWaveAfterGameOver(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.TowersPhase)
+
+	state := hub.GetRoomState("room")
+	state.Mutex.Lock()
+	state.GameOver = true
+	state.Mutex.Unlock()
+
+	sendMessage(t, client, "start_wave", nil)
+	expectError(t, client, "game_over")
+}
+
+func TestStartWaveMarksAssistedRuns(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.TowersPhase)
+
+	// Alice leaked heavily last wave
+	state := hub.GetRoomState("room")
+	state.Mutex.Lock()
+	state.Director = game.NewDirector()
+	state.Director.RecordWave("alice", 10)
+	state.Mutex.Unlock()
+
+	sendMessage(t, client, "start_wave", nil)
+
+	var started struct {
+		Difficulty *models.DifficultyAdjustment `json:"difficulty"`
+	}
+	decodePayload(t, expectMessage(t, client, "wave_started"), &started)
+	if started.Difficulty == nil || !started.Difficulty.Assisted {
+		t.Fatalf("got difficulty %+v, want an assisted adjustment", started.Difficulty)
+	}
+
+	state.Mutex.Lock()
+	assisted := state.Assisted
+	state.Mutex.Unlock()
+	if !assisted {
+		t.Fatal("run wasn't marked as assisted")
+	}
+}
+
+func TestWaveStopsWhenRoomEmpties(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.TowersPhase)
+	state := hub.GetRoomState("room")
+
+	sendMessage(t, client, "start_wave", nil)
+	expectMessage(t, client, "wave_started")
+
+	hub.Unregister <- client
+	waitForSimulationEnd(t, state)
+
+	// The abandoned wave never completes
+	state.Mutex.Lock()
+	defer state.Mutex.Unlock()
+	if state.Phase != game.CombatPhase {
+		t.Fatalf("room moved on to %s after it emptied", state.Phase)
+	}
+	if len(state.RunStats) != 0 {
+		t.Fatalf("recorded stats for %d towers after the room emptied", len(state.RunStats))
+	}
+}
+
+// weakWave returns a wave of a single enemy or boss that dies to the first shot of a tower placed where it spawns
+func weakWave(t *testing.T, state *RoomState, def game.WaveDefinition) models.EnemyWave {
+	t.Helper()
+
+	state.Mutex.Lock()
+	defer state.Mutex.Unlock()
+
+	wave := game.CreateEnemyWave(def, state.Map)
+	if len(wave.Enemies) != 1 {
+		t.Fatalf("got a wave of %d enemies, want 1", len(wave.Enemies))
+	}
+	enemy := &wave.Enemies[0]
+	enemy.Health, enemy.MaxHealth, enemy.Shield = 1, 1, 0
+
+	tower, err := game.CreateTower("alice", game.BasicTower, enemy.X, enemy.Y)
+	if err != nil {
+		t.Fatalf("creating tower: %v", err)
+	}
+	state.Towers = append(state.Towers, tower)
+	return wave
+}
+
+func TestKillsPayTheWaveOwner(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.CombatPhase)
+	setGold(hub, "room", "alice", 10)
+	state := hub.GetRoomState("room")
+
+	wave := weakWave(t, state, game.WaveDefinition{
+		Level:  1,
+		Groups: []game.WaveGroup{{EnemyType: "basic", Count: 1}},
+	})
+	wave.Enemies[0].Gold = 7
+	go hub.runWave(state, wave, "alice")
+
+	var completed struct {
+		Gold      int `json:"gold"`
+		TotalGold int `json:"totalGold"`
+	}
+	decodePayload(t, expectMessage(t, client, "wave_completed"), &completed)
+	if completed.Gold != 7 || completed.TotalGold != 17 {
+		t.Fatalf("got %d gold for a total of %d, want 7 for a total of 17", completed.Gold, completed.TotalGold)
+	}
+
+	state.Mutex.Lock()
+	defer state.Mutex.Unlock()
+	if gold := state.Player("alice").Gold; gold != 17 {
+		t.Fatalf("alice has %d gold, want 17", gold)
+	}
+}
+
+func TestBossRewardsPayTheWaveOwner(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.CombatPhase)
+	setGold(hub, "room", "alice", 0)
+	state := hub.GetRoomState("room")
+
+	boss, err := game.GetBossDefinition("warlord")
+	if err != nil {
+		t.Fatalf("getting boss: %v", err)
+	}
+	wave := weakWave(t, state, game.WaveDefinition{
+		Level:  1,
+		Bosses: []game.WaveBoss{{BossID: boss.ID}},
+	})
+	go hub.runWave(state, wave, "alice")
+
+	var completed struct {
+		Score     int `json:"score"`
+		TotalGold int `json:"totalGold"`
+	}
+	decodePayload(t, expectMessage(t, client, "wave_completed"), &completed)
+	if completed.TotalGold != boss.Rewards.Gold || completed.Score != boss.Rewards.Score {
+		t.Fatalf("got %d gold and %d score for the boss, want %d and %d", completed.TotalGold, completed.Score, boss.Rewards.Gold, boss.Rewards.Score)
+	}
+}
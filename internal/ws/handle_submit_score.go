@@ -0,0 +1,33 @@
+package ws
+
+import (
+	"fmt"
+	"time"
+)
+
+func init() {
+	register("submit_score", handleSubmitScore, authenticated, inRoom, inPhase, rateLimited(1, time.Second))
+}
+
+// submitScorePayload is the payload of submit_score
+type submitScorePayload struct {
+	Name string `json:"name"`
+}
+
+// Validate checks that the name fits on the leaderboard
+func (p submitScorePayload) Validate() error {
+	if p.Name == "" || len(p.Name) > 50 {
+		return fmt.Errorf("invalid name %q", p.Name)
+	}
+	return nil
+}
+
+// handleSubmitScore puts the player's score on the leaderboard.
+// The score comes from the server's own record of the game.
+func handleSubmitScore(req *Request, payload submitScorePayload) error {
+	result, err := req.Hub.submitScore(req.Client, payload.Name)
+	if err != nil {
+		return err
+	}
+	return req.Reply("score_submitted", result)
+}
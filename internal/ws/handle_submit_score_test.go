@@ -0,0 +1,53 @@
+package ws
+
+import (
+	"errors"
+	"testing"
+
+	"realtime-game-backend/internal/game"
+)
+
+func TestSubmitScoreOnlyAtTheEnd(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	sendMessage(t, client, "submit_score", submitScorePayload{Name: "Alice"})
+	expectError(t, client, "wrong_phase")
+}
+
+func TestSubmitScoreBeforeGameOver(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.EndPhase)
+
+	sendMessage(t, client, "submit_score", submitScorePayload{Name: "Alice"})
+	expectError(t, client, "game_not_over")
+}
+
+func TestSubmitScoreNeedsName(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.EndPhase)
+
+	sendMessage(t, client, "submit_score", submitScorePayload{})
+	expectError(t, client, "invalid_payload")
+}
+
+func TestSubmitScoreCanRetryAfterFailure(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.EndPhase)
+
+	state := hub.GetRoomState("room")
+	state.Mutex.Lock()
+	state.GameOver = true
+	state.Player("alice").Score = 100
+	state.Mutex.Unlock()
+
+	// Without a database the score is never saved, so every attempt fails the same way
+	for i := 0; i < 2; i++ {
+		if _, err := hub.submitScore(client, "Alice"); !errors.Is(err, ErrLeaderboardDisabled) {
+			t.Fatalf("attempt %d: got %v, want %v", i+1, err, ErrLeaderboardDisabled)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package ws
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"realtime-game-backend/internal/game"
+)
+
+func init() {
+	register("upgrade_tower", handleUpgradeTower, authenticated, inRoom, inPhase, rateLimited(10, time.Second))
+}
+
+// Error definitions
+var (
+	ErrTowerNotFound = errors.New("tower not found")
+	ErrNotTowerOwner = errors.New("only the owner can upgrade a tower")
+)
+
+// towerPayload identifies a tower in the room
+type towerPayload struct {
+	TowerID string `json:"towerId"`
+}
+
+// Validate checks that a tower is given
+func (p towerPayload) Validate() error {
+	if p.TowerID == "" {
+		return errors.New("towerId is required")
+	}
+	return nil
+}
+
+// handleUpgradeTower upgrades a tower to its next level if the player can afford it
+func handleUpgradeTower(req *Request, payload towerPayload) error {
+	playerID := req.PlayerID()
+	log.Printf("Player %s is upgrading tower %s", playerID, payload.TowerID)
+
+	// Find the tower in the room and upgrade it
+	state := req.State()
+	state.Mutex.Lock()
+	index := -1
+	for i, existing := range state.Towers {
+		if existing.ID == payload.TowerID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		state.Mutex.Unlock()
+		return fmt.Errorf("%w: %s", ErrTowerNotFound, payload.TowerID)
+	}
+
+	if state.Towers[index].PlayerID != playerID {
+		state.Mutex.Unlock()
+		return fmt.Errorf("%w: %s", ErrNotTowerOwner, payload.TowerID)
+	}
+
+	tower, err := game.UpgradeTower(state.Towers[index])
+	if err != nil {
+		state.Mutex.Unlock()
+		return err
+	}
+
+	player := state.Player(playerID)
+	upgradeCost := game.GetTowerUpgradeCost(state.Towers[index])
+	if player.Gold < upgradeCost {
+		state.Mutex.Unlock()
+		return fmt.Errorf("%w to upgrade tower %s (%d of %d gold)", ErrNotEnoughGold, payload.TowerID, player.Gold, upgradeCost)
+	}
+
+	state.Towers[index] = tower
+	if state.Simulation != nil {
+		state.Simulation.ReplaceTower(tower)
+	}
+	player.Gold -= upgradeCost
+	state.Mutex.Unlock()
+
+	log.Printf("Sending tower_upgraded response to room %s", req.Msg.RoomID)
+
+	return req.Broadcast("tower_upgraded", map[string]interface{}{
+		"tower": tower,
+	})
+}
@@ -0,0 +1,91 @@
+package ws
+
+import (
+	"testing"
+
+	"realtime-game-backend/internal/game"
+	"realtime-game-backend/internal/models"
+)
+
+func TestUpgradeTower(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.TowersPhase)
+
+	x, y := buildableSpot(t, hub, "room")
+	tower := placeTower(t, client, x, y)
+	setGold(hub, "room", "alice", 1000)
+
+	sendMessage(t, client, "upgrade_tower", towerPayload{TowerID: tower.ID})
+
+	var upgraded struct {
+		Tower models.Tower `json:"tower"`
+	}
+	decodePayload(t, expectMessage(t, client, "tower_upgraded"), &upgraded)
+	if upgraded.Tower.ID != tower.ID || upgraded.Tower.Level != tower.Level+1 {
+		t.Fatalf("got tower %+v after upgrading %+v", upgraded.Tower, tower)
+	}
+}
+
+func TestUpgradeTowerNeedsGold(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.TowersPhase)
+
+	x, y := buildableSpot(t, hub, "room")
+	tower := placeTower(t, client, x, y)
+	setGold(hub, "room", "alice", 0)
+
+	sendMessage(t, client, "upgrade_tower", towerPayload{TowerID: tower.ID})
+	expectError(t, client, "insufficient_gold")
+}
+
+func TestUpgradeUnknownTower(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.TowersPhase)
+
+	sendMessage(t, client, "upgrade_tower", towerPayload{TowerID: "missing"})
+	expectError(t, client, "tower_not_found")
+}
+
+func TestUpgradeTowerNeedsTowerID(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.TowersPhase)
+
+	sendMessage(t, client, "upgrade_tower", towerPayload{})
+	expectError(t, client, "invalid_payload")
+}
+
+func TestUpgradeTowerOwnedByAnotherPlayer(t *testing.T) {
+	hub := newTestHub(t)
+	alice := joinTestClient(t, hub, "alice", "room")
+	bob := joinTestClient(t, hub, "bob", "room")
+	setPhase(hub, "room", game.TowersPhase)
+
+	x, y := buildableSpot(t, hub, "room")
+	tower := placeTower(t, alice, x, y)
+	setGold(hub, "room", "bob", 1000)
+
+	sendMessage(t, bob, "upgrade_tower", towerPayload{TowerID: tower.ID})
+	expectError(t, bob, "not_owner")
+}
+
+func TestUpgradeTowerAtMaxLevel(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+	setPhase(hub, "room", game.TowersPhase)
+
+	x, y := buildableSpot(t, hub, "room")
+	tower := placeTower(t, client, x, y)
+	setGold(hub, "room", "alice", 100000)
+
+	for level := tower.Level; level < game.GetTowerCatalog().MaxLevel(tower.Type); level++ {
+		sendMessage(t, client, "upgrade_tower", towerPayload{TowerID: tower.ID})
+		expectMessage(t, client, "tower_upgraded")
+	}
+
+	sendMessage(t, client, "upgrade_tower", towerPayload{TowerID: tower.ID})
+	expectError(t, client, "max_level")
+}
@@ -0,0 +1,28 @@
+package ws
+
+import (
+	"log"
+	"time"
+)
+
+func init() {
+	register("wave_preview", handleWavePreview, authenticated, inRoom, inPhase, rateLimited(5, time.Second))
+}
+
+// wavePreviewPayload is the payload of wave_preview
+type wavePreviewPayload struct {
+	Count int `json:"count"` // Number of upcoming waves, defaults to 3
+}
+
+// handleWavePreview sends the upcoming waves to the player.
+// Only the requesting player sees the preview, since scouting is per player.
+func handleWavePreview(req *Request, payload wavePreviewPayload) error {
+	log.Printf("Sending wave_preview to player %s", req.PlayerID())
+
+	state := req.State()
+	state.Mutex.Lock()
+	preview := state.previewPayload(req.PlayerID(), payload.Count)
+	state.Mutex.Unlock()
+
+	return req.Reply("wave_preview", preview)
+}
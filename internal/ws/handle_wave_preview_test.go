@@ -0,0 +1,23 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestWavePreview(t *testing.T) {
+	hub := newTestHub(t)
+	alice := joinTestClient(t, hub, "alice", "room")
+	bob := joinTestClient(t, hub, "bob", "room")
+
+	sendMessage(t, alice, "wave_preview", wavePreviewPayload{Count: 2})
+
+	var preview struct {
+		Waves []json.RawMessage `json:"waves"`
+	}
+	decodePayload(t, expectMessage(t, alice, "wave_preview"), &preview)
+	if len(preview.Waves) != 2 {
+		t.Fatalf("got %d waves, want 2", len(preview.Waves))
+	}
+	expectNoMessage(t, bob, "wave_preview")
+}
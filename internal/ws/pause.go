@@ -0,0 +1,184 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"realtime-game-backend/internal/game"
+)
+
+// Pause limits
+const (
+	maxPausesPerPlayer = 3                // Pauses each player can ask for in a game
+	maxPauseDuration   = 10 * time.Minute // Paused games resume on their own after this long
+)
+
+// Error definitions
+var (
+	ErrGamePaused    = errors.New("the game is paused")
+	ErrGameNotPaused = errors.New("the game isn't paused")
+	ErrNoPausesLeft  = errors.New("no pauses left")
+	ErrAlreadyVoted  = errors.New("already voted to pause")
+)
+
+// pauseVoteList returns the sorted IDs of the players who voted to pause.
+// The caller must hold the room mutex.
+func (r *RoomState) pauseVoteList() []string {
+	votes := make([]string, 0, len(r.pauseVotes))
+	for playerID := range r.pauseVotes {
+		votes = append(votes, playerID)
+	}
+	sort.Strings(votes)
+	return votes
+}
+
+// waveElapsed returns how far the current wave has progressed in milliseconds, or -1 between waves.
+// The caller must hold the room mutex.
+func (r *RoomState) waveElapsed() int64 {
+	if r.Simulation == nil {
+		return -1
+	}
+	return r.Simulation.Elapsed
+}
+
+// checkPaused checks that a message type can be handled while a room is paused.
+// Every action restricted to some phases waits for the game to resume.
+func (h *Hub) checkPaused(roomID, msgType string) error {
+	state := h.GetRoomState(roomID)
+	state.Mutex.Lock()
+	defer state.Mutex.Unlock()
+
+	if state.Paused && game.PhaseRestricted(msgType) {
+		return ErrGamePaused
+	}
+	return nil
+}
+
+// votePause records a player's vote to pause a room's game. A player alone in the room pauses it
+// right away; otherwise the game pauses once more than half of the connected players voted.
+// Each pause counts against the player who asked for it.
+func (h *Hub) votePause(state *RoomState, playerID string) error {
+	connected := h.roomPlayerIDs(state.ID)
+
+	state.Mutex.Lock()
+	if state.GameOver {
+		state.Mutex.Unlock()
+		return ErrGameOver
+	}
+	if state.Paused {
+		state.Mutex.Unlock()
+		return ErrGamePaused
+	}
+	if state.pauseVotes[playerID] {
+		state.Mutex.Unlock()
+		return ErrAlreadyVoted
+	}
+
+	// The first vote asks for the pause
+	if len(state.pauseVotes) == 0 {
+		if state.PausesUsed[playerID] >= maxPausesPerPlayer {
+			state.Mutex.Unlock()
+			return ErrNoPausesLeft
+		}
+		state.pauseRequester = playerID
+	}
+	state.pauseVotes[playerID] = true
+
+	votes := state.pauseVoteList()
+	needed := len(connected)/2 + 1
+	if len(votes) < needed {
+		requester := state.pauseRequester
+		state.Mutex.Unlock()
+
+		h.broadcastPauseEvent(state.ID, "pause_vote", map[string]interface{}{
+			"playerId":    playerID,
+			"requestedBy": requester,
+			"votes":       votes,
+			"needed":      needed,
+		})
+		return nil
+	}
+
+	requester := state.pauseRequester
+	state.PausesUsed[requester]++
+	state.Paused = true
+	state.pausedAt = time.Now()
+	state.pauseVotes = make(map[string]bool)
+	pausedAt := state.pausedAt
+	payload := map[string]interface{}{
+		"requestedBy": requester,
+		"votes":       votes,
+		"pausesLeft":  maxPausesPerPlayer - state.PausesUsed[requester],
+		"phase":       state.Phase,
+		"waveElapsed": state.waveElapsed(),
+		"maxDuration": maxPauseDuration.Milliseconds(),
+	}
+	state.Mutex.Unlock()
+
+	log.Printf("Game paused in room %s by player %s", state.ID, requester)
+
+	h.broadcastPauseEvent(state.ID, "game_paused", payload)
+
+	go h.autoResume(state, pausedAt)
+	return nil
+}
+
+// resumeGame resumes a room's paused game on behalf of a player, or of the server if playerID is empty
+func (h *Hub) resumeGame(state *RoomState, playerID string) error {
+	state.Mutex.Lock()
+	if !state.Paused {
+		state.Mutex.Unlock()
+		return ErrGameNotPaused
+	}
+	state.Paused = false
+	payload := map[string]interface{}{
+		"playerId":    playerID,
+		"pausedFor":   time.Since(state.pausedAt).Milliseconds(),
+		"phase":       state.Phase,
+		"waveElapsed": state.waveElapsed(),
+	}
+	state.Mutex.Unlock()
+
+	log.Printf("Game resumed in room %s", state.ID)
+
+	h.broadcastPauseEvent(state.ID, "game_resumed", payload)
+	return nil
+}
+
+// autoResume resumes a game that's still in the pause started at pausedAt once it hits the maximum duration.
+// It gives up if the room is dropped first.
+func (h *Hub) autoResume(state *RoomState, pausedAt time.Time) {
+	select {
+	case <-time.After(maxPauseDuration):
+	case <-state.closed:
+		return
+	}
+
+	state.Mutex.Lock()
+	samePause := state.Paused && state.pausedAt.Equal(pausedAt)
+	state.Mutex.Unlock()
+
+	if samePause {
+		if err := h.resumeGame(state, ""); err != nil {
+			log.Printf("Error resuming room %s: %v", state.ID, err)
+		}
+	}
+}
+
+// broadcastPauseEvent broadcasts a pause vote or a pause change to a room
+func (h *Hub) broadcastPauseEvent(roomID, msgType string, payload map[string]interface{}) {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error marshaling payload: %v", err)
+		return
+	}
+
+	h.BroadcastToRoom(roomID, &Message{
+		Type:     msgType,
+		Payload:  payloadJSON,
+		SenderID: "server",
+	})
+}
@@ -0,0 +1,101 @@
+package ws
+
+import (
+	"encoding/json"
+	"log"
+
+	"realtime-game-backend/internal/game"
+)
+
+// transition moves the room to another phase and returns the phase_changed payload.
+// Entering the cards phase starts a new round, and every change resets the ready check.
+// The caller must hold the room mutex, and should announce the change with phaseChanged after unlocking it.
+func (r *RoomState) transition(next string) (map[string]interface{}, error) {
+	if err := game.CanTransition(r.Phase, next); err != nil {
+		return nil, err
+	}
+
+	previous := r.Phase
+	r.Phase = next
+	r.phaseSeq++
+	r.ready = make(map[string]bool)
+	if next == game.CardsPhase {
+		r.Round++
+		r.handsPlayed = make(map[string]bool)
+	}
+
+	return r.phasePayload(previous), nil
+}
+
+// handPlayed marks a player's hand as played for the round and reports whether
+// every player whose base still stands has played theirs. The caller must hold the room mutex.
+func (r *RoomState) handPlayed(playerID string) bool {
+	r.handsPlayed[playerID] = true
+	for id := range r.Players {
+		if r.baseAlive(id) && !r.handsPlayed[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// phasePayload builds the payload describing the room's phase to clients.
+// The caller must hold the room mutex.
+func (r *RoomState) phasePayload(previous string) map[string]interface{} {
+	return map[string]interface{}{
+		"phase":          r.Phase,
+		"previous":       previous,
+		"round":          r.Round,
+		"allowedActions": game.AllowedActions(r.Phase),
+	}
+}
+
+// checkPhase checks that a message type is allowed in a room's current phase
+func (h *Hub) checkPhase(roomID, msgType string) error {
+	state := h.GetRoomState(roomID)
+	state.Mutex.Lock()
+	defer state.Mutex.Unlock()
+
+	return game.CheckAction(state.Phase, msgType)
+}
+
+// broadcastPhaseChange broadcasts phase_changed to a room after it moved to a new phase
+func (h *Hub) broadcastPhaseChange(roomID string, payload map[string]interface{}) {
+	log.Printf("Room %s moved from the %s phase to the %s phase", roomID, payload["previous"], payload["phase"])
+
+	// Marshal payload to JSON
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error marshaling payload: %v", err)
+		return
+	}
+
+	h.BroadcastToRoom(roomID, &Message{
+		Type:     "phase_changed",
+		Payload:  payloadJSON,
+		SenderID: "server",
+	})
+}
+
+// sendRoomPhase sends phase_changed with the room's current phase to a single client.
+// Only the hub goroutine may call it, when the client registers.
+func (h *Hub) sendRoomPhase(client *Client) {
+	state := h.GetRoomState(client.RoomID)
+	state.Mutex.Lock()
+	payload := state.phasePayload("")
+	state.Mutex.Unlock()
+
+	// Marshal payload to JSON
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error marshaling payload: %v", err)
+		return
+	}
+
+	h.sendToClient(client, &Message{
+		Type:     "phase_changed",
+		Payload:  payloadJSON,
+		RoomID:   client.RoomID,
+		SenderID: "server",
+	})
+}
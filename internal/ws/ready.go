@@ -0,0 +1,277 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"realtime-game-backend/internal/game"
+)
+
+// timedPhases lists the phases that can have a countdown; combat lasts as long as its wave
+var timedPhases = map[string]bool{
+	game.SetupPhase:  true,
+	game.CardsPhase:  true,
+	game.TowersPhase: true,
+}
+
+// ParsePhaseTimers parses per-phase countdowns such as "cards=30s,towers=45s"
+func ParsePhaseTimers(spec string) (map[string]time.Duration, error) {
+	timers := make(map[string]time.Duration)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		phase, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("phase timer %q must look like phase=duration", entry)
+		}
+		if !timedPhases[phase] {
+			return nil, fmt.Errorf("phase %q can't have a timer", phase)
+		}
+
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("phase timer %q: %w", entry, err)
+		}
+		if duration < time.Second {
+			return nil, fmt.Errorf("phase timer %q must be at least a second", entry)
+		}
+		timers[phase] = duration
+	}
+	return timers, nil
+}
+
+// roomPlayerIDs returns the IDs of the players connected to a room
+func (h *Hub) roomPlayerIDs(roomID string) []string {
+	h.Mutex.RLock()
+	defer h.Mutex.RUnlock()
+
+	seen := make(map[string]bool)
+	var playerIDs []string
+	for _, client := range h.Rooms[roomID] {
+		if client.PlayerID != "" && !seen[client.PlayerID] {
+			seen[client.PlayerID] = true
+			playerIDs = append(playerIDs, client.PlayerID)
+		}
+	}
+	return playerIDs
+}
+
+// leaveRoom stops tracking a disconnected player in their room
+func (h *Hub) leaveRoom(client *Client) {
+	if h.Redis == nil || client.RoomID == "" || client.PlayerID == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Redis.RemovePlayerFromRoom(ctx, client.RoomID, client.PlayerID); err != nil {
+		log.Printf("Error removing player %s from room %s: %v", client.PlayerID, client.RoomID, err)
+	}
+}
+
+// setPlayerReady marks a player as ready for the room to move on and returns the ready players
+// and whether every player in the room is ready. Readiness is kept in Redis when it's available.
+func (h *Hub) setPlayerReady(state *RoomState, playerID string) ([]string, bool, error) {
+	if h.Redis != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := h.Redis.SetPlayerReady(ctx, state.ID, playerID); err != nil {
+			return nil, false, err
+		}
+		allReady, err := h.Redis.IsRoomReady(ctx, state.ID)
+		if err != nil {
+			return nil, false, err
+		}
+		ready, err := h.Redis.GetReadyPlayersInRoom(ctx, state.ID)
+		if err != nil {
+			return nil, false, err
+		}
+		sort.Strings(ready)
+		return ready, allReady, nil
+	}
+
+	// Without Redis, compare against the players connected to this server
+	members := h.roomPlayerIDs(state.ID)
+
+	state.Mutex.Lock()
+	defer state.Mutex.Unlock()
+
+	state.ready[playerID] = true
+	allReady := len(members) > 0
+	for _, member := range members {
+		if !state.ready[member] {
+			allReady = false
+		}
+	}
+
+	ready := make([]string, 0, len(state.ready))
+	for id := range state.ready {
+		ready = append(ready, id)
+	}
+	sort.Strings(ready)
+	return ready, allReady, nil
+}
+
+// clearReady resets every player's readiness in Redis after the room changed phase.
+// Without Redis, transition resets it along with the phase.
+func (h *Hub) clearReady(state *RoomState) {
+	if h.Redis == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Redis.ClearRoomReady(ctx, state.ID); err != nil {
+		log.Printf("Error clearing ready players for room %s: %v", state.ID, err)
+	}
+}
+
+// phaseChanged broadcasts phase_changed after a room moved to a new phase,
+// resets the ready check and starts the new phase's countdown if one is configured
+func (h *Hub) phaseChanged(state *RoomState, payload map[string]interface{}) {
+	h.clearReady(state)
+	h.broadcastPhaseChange(state.ID, payload)
+
+	phase, _ := payload["phase"].(string)
+	if duration, ok := h.PhaseTimers[phase]; ok && timedPhases[phase] {
+		state.Mutex.Lock()
+		seq := state.phaseSeq
+		state.Mutex.Unlock()
+
+		go h.runPhaseTimer(state, seq, phase, duration)
+	}
+}
+
+// runPhaseTimer broadcasts a countdown every second while a room stays in a phase,
+// and moves the room on when it runs out. The countdown stops while the game is paused,
+// and ends when the room's state is dropped.
+func (h *Hub) runPhaseTimer(state *RoomState, seq int, phase string, duration time.Duration) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	remaining := int(duration / time.Second)
+	announced := -1
+	for {
+		state.Mutex.Lock()
+		current := state.phaseSeq == seq
+		paused := state.Paused
+		round := state.Round
+		state.Mutex.Unlock()
+		if !current {
+			return
+		}
+
+		if !paused {
+			if remaining != announced {
+				h.broadcastCountdown(state.ID, phase, round, remaining)
+				announced = remaining
+			}
+			if remaining == 0 {
+				log.Printf("Phase timer for %s ran out in room %s", phase, state.ID)
+				h.advancePhase(state, seq, "")
+				return
+			}
+		}
+
+		select {
+		case <-state.closed:
+			return
+		case <-ticker.C:
+		}
+
+		// Check again in case the game was paused during the second
+		state.Mutex.Lock()
+		paused = paused || state.Paused
+		state.Mutex.Unlock()
+		if !paused {
+			remaining--
+		}
+	}
+}
+
+// broadcastCountdown broadcasts phase_countdown to a room
+func (h *Hub) broadcastCountdown(roomID, phase string, round, remaining int) {
+	payloadJSON, err := json.Marshal(map[string]interface{}{
+		"phase":     phase,
+		"round":     round,
+		"remaining": remaining, // Seconds
+	})
+	if err != nil {
+		log.Printf("Error marshaling payload: %v", err)
+		return
+	}
+
+	h.BroadcastToRoom(roomID, &Message{
+		Type:     "phase_countdown",
+		Payload:  payloadJSON,
+		SenderID: "server",
+	})
+}
+
+// advancePhase moves a room on to its next phase when every player is ready or its timer runs out.
+// Nothing happens if the room has left the phase identified by seq in the meantime.
+// Leaving the towers phase starts the next wave on behalf of ownerID, or of another player if it's empty.
+func (h *Hub) advancePhase(state *RoomState, seq int, ownerID string) {
+	state.Mutex.Lock()
+	if state.phaseSeq != seq {
+		state.Mutex.Unlock()
+		return
+	}
+
+	var next string
+	switch state.Phase {
+	case game.SetupPhase:
+		next = game.CardsPhase
+	case game.CardsPhase:
+		next = game.TowersPhase
+	case game.TowersPhase:
+		state.Mutex.Unlock()
+		if err := h.startWave(state, ownerID); err != nil {
+			log.Printf("Error starting wave in room %s: %v", state.ID, err)
+		}
+		return
+	default:
+		state.Mutex.Unlock()
+		return
+	}
+
+	// Players who haven't played their hand by now sit this round out
+	phaseChange, err := state.transition(next)
+	state.Mutex.Unlock()
+	if err != nil {
+		log.Printf("Error advancing room %s: %v", state.ID, err)
+		return
+	}
+
+	h.phaseChanged(state, phaseChange)
+}
+
+// broadcastReady broadcasts ready_changed to a room after a player became ready
+func (h *Hub) broadcastReady(roomID, playerID string, ready []string, allReady bool) {
+	payloadJSON, err := json.Marshal(map[string]interface{}{
+		"playerId":     playerID,
+		"readyPlayers": ready,
+		"allReady":     allReady,
+	})
+	if err != nil {
+		log.Printf("Error marshaling payload: %v", err)
+		return
+	}
+
+	h.BroadcastToRoom(roomID, &Message{
+		Type:     "ready_changed",
+		Payload:  payloadJSON,
+		SenderID: "server",
+	})
+}
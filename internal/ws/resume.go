@@ -0,0 +1,188 @@
+package ws
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"time"
+
+	"realtime-game-backend/internal/models"
+)
+
+// defaultResumeGrace is how long a disconnected player's state is kept by default
+const defaultResumeGrace = 2 * time.Minute
+
+// resumeSession holds the state of a disconnected player until they reconnect or the grace period ends
+type resumeSession struct {
+	Token    string
+	PlayerID string
+	RoomID   string
+	timer    *time.Timer
+}
+
+// tokenSource is where resume tokens get their randomness
+var tokenSource io.Reader = rand.Reader
+
+// newResumeToken generates a random token a client can reconnect with.
+// The token is the only credential needed to take over a session, so it must not be guessable.
+func newResumeToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := io.ReadFull(tokenSource, buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// parkClient keeps a disconnected client's state so it can resume with its token.
+// The caller must hold the hub mutex.
+func (h *Hub) parkClient(client *Client) {
+	if client.ResumeToken == "" || client.PlayerID == "" || h.ResumeGrace <= 0 {
+		return
+	}
+
+	session := &resumeSession{
+		Token:    client.ResumeToken,
+		PlayerID: client.PlayerID,
+		RoomID:   client.RoomID,
+	}
+	session.timer = time.AfterFunc(h.ResumeGrace, func() {
+		h.expireResumable(session)
+	})
+	h.resumable[session.Token] = session
+
+	log.Printf("Keeping state of player %s for %s", client.PlayerID, h.ResumeGrace)
+}
+
+// takeResumable removes and returns the parked state for a resume token, or nil if there is none
+func (h *Hub) takeResumable(token string) *resumeSession {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	session, ok := h.resumable[token]
+	if !ok {
+		return nil
+	}
+	session.timer.Stop()
+	delete(h.resumable, token)
+	return session
+}
+
+// expireResumable drops a parked session once its grace period is over,
+// along with its room's state if nobody is left to play in it
+func (h *Hub) expireResumable(session *resumeSession) {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	if h.resumable[session.Token] != session {
+		return
+	}
+	delete(h.resumable, session.Token)
+
+	if len(h.Rooms[session.RoomID]) == 0 && !h.roomResumable(session.RoomID) {
+		h.dropRoomState(session.RoomID)
+	}
+
+	log.Printf("Resume grace period ended for player %s", session.PlayerID)
+}
+
+// roomResumable reports whether a disconnected player can still come back to a room.
+// The caller must hold the hub mutex.
+func (h *Hub) roomResumable(roomID string) bool {
+	for _, session := range h.resumable {
+		if session.RoomID == roomID {
+			return true
+		}
+	}
+	return false
+}
+
+// disconnect removes a client from the hub, keeping its state for the resume grace period.
+// The room's state is dropped once nobody is connected to it or can resume in it.
+// The caller must hold the hub mutex.
+func (h *Hub) disconnect(client *Client) {
+	delete(h.Clients, client.ID)
+	close(client.Send)
+	client.disconnected = true
+	h.parkClient(client)
+
+	if client.RoomID != "" && h.Rooms[client.RoomID] != nil {
+		delete(h.Rooms[client.RoomID], client.ID)
+		if len(h.Rooms[client.RoomID]) == 0 {
+			delete(h.Rooms, client.RoomID)
+			if !h.roomResumable(client.RoomID) {
+				h.dropRoomState(client.RoomID)
+			}
+		}
+	}
+}
+
+// resumeClient puts a reconnected client back in its parked session's room.
+// The player's hand waits for them in the room's state.
+func (c *Client) resumeClient(session *resumeSession) {
+	c.PlayerID = session.PlayerID
+	c.RoomID = session.RoomID
+	c.resumed = true
+}
+
+// sendSession sends session with the client's resume token and whether it resumed an earlier connection
+func (h *Hub) sendSession(client *Client) {
+	payload := map[string]interface{}{
+		"playerId":    client.PlayerID,
+		"roomId":      client.RoomID,
+		"resumed":     client.resumed,
+		"gracePeriod": h.ResumeGrace.Milliseconds(),
+	}
+
+	// Clients without a token can't resume
+	if client.ResumeToken != "" {
+		payload["resumeToken"] = client.ResumeToken
+	}
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error marshaling payload: %v", err)
+		return
+	}
+
+	h.sendToClient(client, &Message{
+		Type:     "session",
+		Payload:  payloadJSON,
+		RoomID:   client.RoomID,
+		SenderID: "server",
+	})
+}
+
+// sendGameState sends game_state with a full snapshot of the client's room, including its own hand
+// and the wave being fought
+func (h *Hub) sendGameState(client *Client) {
+	state := h.GetRoomState(client.RoomID)
+	state.Mutex.Lock()
+	snapshot := state.snapshot(client.PlayerID)
+	if state.Simulation != nil {
+		wave := state.Simulation.Wave
+		wave.Enemies = append([]models.Enemy(nil), wave.Enemies...)
+		snapshot.CurrentWave = &wave
+	}
+	payload := struct {
+		models.GameState
+		Paused      bool  `json:"paused"`
+		WaveElapsed int64 `json:"waveElapsed"` // Milliseconds into the current wave, -1 between waves
+	}{snapshot, state.Paused, state.waveElapsed()}
+	state.Mutex.Unlock()
+
+	// Marshal payload to JSON
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error marshaling payload: %v", err)
+		return
+	}
+
+	h.sendToClient(client, &Message{
+		Type:     "game_state",
+		Payload:  payloadJSON,
+		RoomID:   client.RoomID,
+		SenderID: "server",
+	})
+}
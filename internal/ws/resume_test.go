@@ -0,0 +1,198 @@
+package ws
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestNewResumeTokenFailsWithoutRandomness(t *testing.T) {
+	failure := errors.New("entropy unavailable")
+	previous := tokenSource
+	tokenSource = iotest.ErrReader(failure)
+	t.Cleanup(func() { tokenSource = previous })
+
+	if token, err := newResumeToken(); !errors.Is(err, failure) || token != "" {
+		t.Fatalf("got token %q and error %v, want no token and %v", token, err, failure)
+	}
+}
+
+func TestClientsWithoutTokenAreNotParked(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	hub.Mutex.Lock()
+	hub.parkClient(client)
+	parked := len(hub.resumable)
+	hub.Mutex.Unlock()
+
+	if parked != 0 {
+		t.Fatalf("parked %d sessions for a client without a resume token", parked)
+	}
+}
+
+// dialTestHub connects a websocket client to a hub served over HTTP with the given query
+func dialTestHub(t *testing.T, server *httptest.Server, query string) *websocket.Conn {
+	t.Helper()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/?"+query, nil)
+	if err != nil {
+		t.Fatalf("dialing hub: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readMessage reads messages from a connection until one of a type arrives
+func readMessage(t *testing.T, conn *websocket.Conn, msgType string) *Message {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var msg Message
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("waiting for %s: %v", msgType, err)
+		}
+		if msg.Type == msgType {
+			return &msg
+		}
+	}
+}
+
+// sessionPayload is the payload of session
+type sessionPayload struct {
+	PlayerID    string `json:"playerId"`
+	RoomID      string `json:"roomId"`
+	Resumed     bool   `json:"resumed"`
+	ResumeToken string `json:"resumeToken"`
+}
+
+// readSession reads the session a connection starts with
+func readSession(t *testing.T, conn *websocket.Conn) sessionPayload {
+	t.Helper()
+
+	var session sessionPayload
+	decodePayload(t, readMessage(t, conn, "session"), &session)
+	return session
+}
+
+// waitForParked waits until the hub holds a session for a resume token, or no longer holds it
+func waitForParked(t *testing.T, hub *Hub, token string, parked bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		hub.Mutex.RLock()
+		_, ok := hub.resumable[token]
+		hub.Mutex.RUnlock()
+		if ok == parked {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("session parked is %v, want %v", ok, parked)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// disconnectParked connects alice to a room with some gold, drops the connection and waits
+// for the hub to park the session. It returns the session's resume token.
+func disconnectParked(t *testing.T, hub *Hub, server *httptest.Server) string {
+	t.Helper()
+
+	conn := dialTestHub(t, server, "playerId=alice&roomId=room")
+	session := readSession(t, conn)
+	if session.ResumeToken == "" {
+		t.Fatal("session has no resume token")
+	}
+	readMessage(t, conn, "phase_changed")
+	setGold(hub, "room", "alice", 77)
+
+	conn.Close()
+	waitForParked(t, hub, session.ResumeToken, true)
+	return session.ResumeToken
+}
+
+func TestResumeWithinGracePeriod(t *testing.T) {
+	hub := newTestHub(t)
+	server := httptest.NewServer(http.HandlerFunc(hub.HandleWebSocket))
+	t.Cleanup(server.Close)
+
+	token := disconnectParked(t, hub, server)
+
+	conn := dialTestHub(t, server, "resumeToken="+token)
+	session := readSession(t, conn)
+	if !session.Resumed || session.PlayerID != "alice" || session.RoomID != "room" || session.ResumeToken != token {
+		t.Fatalf("got session %+v, want alice resumed in room with the same token", session)
+	}
+
+	// The client gets the room it left, with the player's state intact
+	var state struct {
+		Players map[string]struct {
+			Gold int `json:"gold"`
+		} `json:"players"`
+	}
+	decodePayload(t, readMessage(t, conn, "game_state"), &state)
+	if gold := state.Players["alice"].Gold; gold != 77 {
+		t.Fatalf("alice has %d gold after resuming, want 77", gold)
+	}
+
+	hub.Mutex.RLock()
+	defer hub.Mutex.RUnlock()
+	if len(hub.Rooms["room"]) != 1 {
+		t.Fatalf("room has %d clients, want the resumed one", len(hub.Rooms["room"]))
+	}
+	for _, client := range hub.Rooms["room"] {
+		if client.PlayerID != "alice" {
+			t.Fatalf("resumed client plays as %q, want alice", client.PlayerID)
+		}
+	}
+}
+
+func TestResumeTokenCanOnlyBeUsedOnce(t *testing.T) {
+	hub := newTestHub(t)
+	server := httptest.NewServer(http.HandlerFunc(hub.HandleWebSocket))
+	t.Cleanup(server.Close)
+
+	token := disconnectParked(t, hub, server)
+
+	first := dialTestHub(t, server, "resumeToken="+token)
+	if !readSession(t, first).Resumed {
+		t.Fatal("first use of the token didn't resume")
+	}
+
+	// The token was taken by the first connection
+	second := dialTestHub(t, server, "resumeToken="+token)
+	if session := readSession(t, second); session.Resumed || session.PlayerID != "" || session.ResumeToken == token {
+		t.Fatalf("got session %+v from a reused token", session)
+	}
+}
+
+func TestResumeTokenExpires(t *testing.T) {
+	hub := newTestHub(t)
+	hub.ResumeGrace = 50 * time.Millisecond
+	server := httptest.NewServer(http.HandlerFunc(hub.HandleWebSocket))
+	t.Cleanup(server.Close)
+
+	token := disconnectParked(t, hub, server)
+	waitForParked(t, hub, token, false)
+
+	conn := dialTestHub(t, server, "resumeToken="+token)
+	if session := readSession(t, conn); session.Resumed || session.PlayerID != "" {
+		t.Fatalf("got session %+v after the grace period", session)
+	}
+
+	// Nobody can come back to the room, so its state is gone
+	hub.Mutex.RLock()
+	_, kept := hub.States["room"]
+	hub.Mutex.RUnlock()
+	if kept {
+		t.Fatal("room state was kept after the grace period")
+	}
+}
@@ -0,0 +1,374 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"realtime-game-backend/internal/game"
+	"realtime-game-backend/internal/models"
+)
+
+// Starting values for a player joining a room, matching the browser client
+const (
+	startingGold   = 100
+	startingHealth = 20
+)
+
+// Scouting reveals the details of upcoming waves
+const (
+	scoutingCost  = 50 // Gold
+	scoutingWaves = 3  // Number of upcoming waves revealed by one purchase
+)
+
+// Error definitions
+var (
+	ErrNotEnoughGold = errors.New("not enough gold")
+)
+
+// RoomState holds the server-side game state shared by the clients in a room
+type RoomState struct {
+	ID      string
+	Towers  []models.Tower
+	Players map[string]*models.PlayerState
+	Mutex   sync.Mutex
+
+	// Simulation is the wave currently being fought, nil between waves
+	Simulation *game.Simulation
+
+	// RunStats maps tower IDs to their combat statistics for the whole run
+	RunStats map[string]*models.TowerStats
+
+	// Map is the map the room plays on
+	Map *game.MapDefinition
+
+	// Mode is how enemies find their way across the map: fixed lanes or a maze of towers
+	Mode string
+
+	// RunType is where waves come from: the campaign wave set or endless generation from Seed
+	RunType string
+	Seed    int64
+
+	// WaveLevel is the level of the last wave started in the room
+	WaveLevel int
+
+	// waveDefs holds the pre-generated definitions of upcoming waves by level,
+	// so previews match the waves that are eventually fought
+	waveDefs map[int]game.WaveDefinition
+
+	// Scouting maps player IDs to the last wave level they have scouted
+	Scouting map[string]int
+
+	// BaseMode is whether every player defends their own base or the room shares one
+	BaseMode string
+
+	// BaseHealth is the health of the shared base
+	BaseHealth int
+
+	// SessionID identifies the room's game in Postgres, empty until the first wave starts
+	SessionID string
+
+	// GameOver is set once the game has ended; FinalLevel is the wave it ended on
+	GameOver   bool
+	FinalLevel int
+
+	// submittedScores marks the players who submitted their final score
+	submittedScores map[string]bool
+
+	// Phase is the room's place in the game's state machine; Round counts the rounds of cards and combat
+	Phase string
+	Round int
+
+	// handsPlayed marks the players who played their poker hand this round
+	handsPlayed map[string]bool
+
+	// hands maps player IDs to their poker hand
+	hands map[string]*pokerHand
+
+	// phaseSeq counts phase changes so stale ready checks and countdowns can tell the room moved on
+	phaseSeq int
+
+	// ready marks the players ready to leave the current phase when Redis isn't available
+	ready map[string]bool
+
+	// Paused freezes waves and countdowns; pausedAt is when the current pause started
+	Paused   bool
+	pausedAt time.Time
+
+	// pauseVotes marks the players who voted for the pending pause asked for by pauseRequester
+	pauseVotes     map[string]bool
+	pauseRequester string
+
+	// PausesUsed counts the pauses each player asked for in this game
+	PausesUsed map[string]int
+
+	// Director adapts waves to each player when adaptive difficulty is on, nil otherwise
+	Director *game.Director
+
+	// Adjustments logs every change made by the director; Assisted is set once one made a wave easier
+	Adjustments []models.DifficultyAdjustment
+	Assisted    bool
+
+	// closed is closed once the room's state is dropped, stopping the goroutines still running for it
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewRoomState creates a new room state on the default map
+func NewRoomState(roomID string) *RoomState {
+	defaultMap, _ := game.GetMap(game.DefaultMapID)
+
+	return &RoomState{
+		ID:       roomID,
+		Players:  make(map[string]*models.PlayerState),
+		RunStats: make(map[string]*models.TowerStats),
+		Map:      defaultMap,
+		Mode:     game.LanesMode,
+		RunType:  game.CampaignRun,
+		Seed:     time.Now().UnixNano(),
+		waveDefs: make(map[int]game.WaveDefinition),
+		Scouting: make(map[string]int),
+
+		BaseMode:        PlayerBase,
+		BaseHealth:      startingHealth,
+		submittedScores: make(map[string]bool),
+
+		Phase:       game.SetupPhase,
+		handsPlayed: make(map[string]bool),
+		hands:       make(map[string]*pokerHand),
+		ready:       make(map[string]bool),
+
+		pauseVotes: make(map[string]bool),
+		PausesUsed: make(map[string]int),
+
+		closed: make(chan struct{}),
+	}
+}
+
+// Player returns the state of a player in the room, creating it if needed.
+// The caller must hold the room mutex.
+func (r *RoomState) Player(playerID string) *models.PlayerState {
+	player, ok := r.Players[playerID]
+	if !ok {
+		player = &models.PlayerState{
+			PlayerID: playerID,
+			Health:   startingHealth,
+			Gold:     startingGold,
+			IsActive: true,
+		}
+		r.Players[playerID] = player
+	}
+	return player
+}
+
+// WaveDefinition returns the definition of a wave level, generating it from the room's seed the first time.
+// The caller must hold the room mutex.
+func (r *RoomState) WaveDefinition(level int) game.WaveDefinition {
+	def, ok := r.waveDefs[level]
+	if !ok {
+		def = game.WaveDefinitionFor(r.RunType, r.Seed, level)
+		r.waveDefs[level] = def
+	}
+	return def
+}
+
+// SetRunType changes where the room's waves come from and discards pre-generated waves.
+// The caller must hold the room mutex.
+func (r *RoomState) SetRunType(runType string, seed int64) {
+	r.RunType = runType
+	r.Seed = seed
+	r.waveDefs = make(map[int]game.WaveDefinition)
+}
+
+// PreviewWaves previews the count waves after a level for a player.
+// The caller must hold the room mutex.
+func (r *RoomState) PreviewWaves(playerID string, currentLevel, count int) []game.WavePreview {
+	previews := make([]game.WavePreview, 0, count)
+	for level := currentLevel + 1; level <= currentLevel+count; level++ {
+		scouted := level <= r.Scouting[playerID]
+		previews = append(previews, game.PreviewWave(r.WaveDefinition(level), scouted))
+	}
+	return previews
+}
+
+// dropRoomState forgets a room's state and stops the waves and timers running for it.
+// The caller must hold the hub mutex.
+func (h *Hub) dropRoomState(roomID string) {
+	state, ok := h.States[roomID]
+	if !ok {
+		return
+	}
+	delete(h.States, roomID)
+	state.closeOnce.Do(func() {
+		close(state.closed)
+	})
+}
+
+// isClosed reports whether the room's state has been dropped
+func (r *RoomState) isClosed() bool {
+	select {
+	case <-r.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// GetRoomState returns the state for a room, creating it if needed
+func (h *Hub) GetRoomState(roomID string) *RoomState {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	state, ok := h.States[roomID]
+	if !ok {
+		state = NewRoomState(roomID)
+		h.States[roomID] = state
+	}
+	return state
+}
+
+// mapPayload builds the payload describing the room's map to clients.
+// The caller must hold the room mutex.
+func (r *RoomState) mapPayload() map[string]interface{} {
+	lanes := r.Map.LanePaths()
+	if r.Mode == game.MazeMode {
+		if mazeLanes, err := game.MazeLanes(r.Map, r.Towers); err == nil {
+			lanes = mazeLanes
+		}
+	}
+
+	return map[string]interface{}{
+		"map":       r.Map,
+		"mode":      r.Mode,
+		"lanes":     lanes,
+		"buildable": r.Map.Buildable(r.Mode),
+	}
+}
+
+// sendRoomMap sends map_selected with the room's current map to a single client
+func (h *Hub) sendRoomMap(client *Client) {
+	state := h.GetRoomState(client.RoomID)
+	state.Mutex.Lock()
+	payload := state.mapPayload()
+	state.Mutex.Unlock()
+
+	// Marshal payload to JSON
+	mapJSON, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error marshaling map payload: %v", err)
+		return
+	}
+
+	h.sendToClient(client, &Message{
+		Type:     "map_selected",
+		Payload:  mapJSON,
+		RoomID:   client.RoomID,
+		SenderID: "server",
+	})
+}
+
+// sendError sends an error message to a single client after one of its actions was rejected.
+// The correlation ID of the rejected message, if it had one, lets the client tell which action failed.
+func (h *Hub) sendError(client *Client, code, message, correlationID string) {
+	payloadJSON, err := json.Marshal(map[string]interface{}{
+		"code":          code,
+		"message":       message,
+		"correlationId": correlationID,
+	})
+	if err != nil {
+		log.Printf("Error marshaling payload: %v", err)
+		return
+	}
+
+	// Errors go through the hub so they arrive in order with the replies sent before them
+	h.Unicast(client, &Message{
+		Type:          "error",
+		Payload:       payloadJSON,
+		SenderID:      "server",
+		CorrelationID: correlationID,
+	})
+}
+
+// sendToClient sends a message to a single client, dropping it if the client's send buffer is full.
+// Only the hub goroutine may call it, since the hub closes Send when the client disconnects;
+// handlers reply through Unicast instead.
+func (h *Hub) sendToClient(client *Client, message *Message) {
+	select {
+	case client.Send <- encodeMessage(message):
+	default:
+		log.Printf("Send buffer full, dropping %s for client %s", message.Type, client.ID)
+	}
+}
+
+// mazePathsPayload builds the payload describing the routes enemies take through the maze.
+// The caller must hold the room mutex.
+func (r *RoomState) mazePathsPayload() (map[string]interface{}, error) {
+	if r.Simulation != nil {
+		// Enemies already on the field follow their own re-routed paths
+		enemyPaths := make(map[string][]models.Point)
+		for _, enemy := range r.Simulation.Wave.Enemies {
+			if enemy.Active && len(enemy.Path) > 0 {
+				enemyPaths[enemy.ID] = enemy.Path
+			}
+		}
+
+		return map[string]interface{}{
+			"lanes":      r.Simulation.Wave.Lanes,
+			"enemyPaths": enemyPaths,
+		}, nil
+	}
+
+	lanes, err := game.MazeLanes(r.Map, r.Towers)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"lanes": lanes,
+	}, nil
+}
+
+// broadcastMazePaths broadcasts maze_paths_updated to a room after its towers change in maze mode
+func (h *Hub) broadcastMazePaths(state *RoomState) {
+	state.Mutex.Lock()
+	if state.Mode != game.MazeMode {
+		state.Mutex.Unlock()
+		return
+	}
+	payload, err := state.mazePathsPayload()
+	state.Mutex.Unlock()
+
+	if err != nil {
+		log.Printf("Error building maze paths for room %s: %v", state.ID, err)
+		return
+	}
+
+	// Marshal payload to JSON
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error marshaling payload: %v", err)
+		return
+	}
+
+	h.BroadcastToRoom(state.ID, &Message{
+		Type:     "maze_paths_updated",
+		Payload:  payloadJSON,
+		SenderID: "server",
+	})
+}
+
+// previewPayload returns the payload of wave_preview with the upcoming waves a player can see.
+// The caller must hold the state's mutex.
+func (r *RoomState) previewPayload(playerID string, count int) map[string]interface{} {
+	if count <= 0 {
+		count = game.DefaultPreviewWaves
+	}
+	count = min(count, game.MaxPreviewWaves)
+
+	return map[string]interface{}{
+		"currentLevel":   r.WaveLevel,
+		"waves":          r.PreviewWaves(playerID, r.WaveLevel, count),
+		"scoutedThrough": r.Scouting[playerID],
+	}
+}
@@ -0,0 +1,284 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"realtime-game-backend/internal/db"
+	"realtime-game-backend/internal/game"
+)
+
+// Error definitions
+var (
+	ErrUnauthorized = errors.New("message sent on behalf of another player")
+	ErrNoRoom       = errors.New("not in a room")
+	ErrUnknownRoom  = errors.New("not in this room")
+	ErrUnknownType  = errors.New("unknown message type")
+	ErrRateLimited  = errors.New("too many messages")
+)
+
+// internalError is the code of errors clients can't act on, sent without their details
+const internalError = "internal_error"
+
+// errorCodes maps known errors to the codes clients get for them, checked in order
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{game.ErrWrongPhase, "wrong_phase"},
+	{ErrGamePaused, "game_paused"},
+	{ErrGameOver, "game_over"},
+	{ErrBaseDestroyed, "base_destroyed"},
+	{ErrNoPlayers, "no_players"},
+	{ErrGameNotPaused, "not_paused"},
+	{ErrAlreadyVoted, "already_voted"},
+	{ErrNoPausesLeft, "no_pauses_left"},
+	{ErrNotEnoughGold, "insufficient_gold"},
+	{game.ErrUnknownTowerType, "unknown_tower"},
+	{ErrTowerNotFound, "tower_not_found"},
+	{ErrNotTowerOwner, "not_owner"},
+	{game.ErrMaxLevel, "max_level"},
+	{ErrCardNotInHand, "card_not_in_hand"},
+	{ErrSettingsLocked, "settings_locked"},
+	{game.ErrUnknownMap, "unknown_map"},
+	{game.ErrUnknownMode, "invalid_mode"},
+	{game.ErrMazeUnsuitable, "invalid_mode"},
+	{game.ErrUnbuildableTile, "unbuildable"},
+	{game.ErrOutsideMapBounds, "unbuildable"},
+	{game.ErrTileOccupied, "unbuildable"},
+	{game.ErrMazeBlocked, "maze_blocked"},
+	{game.ErrMergeTowerCount, "invalid_merge"},
+	{game.ErrMergeDuplicate, "invalid_merge"},
+	{game.ErrMergeNotOwner, "invalid_merge"},
+	{game.ErrMergeMismatch, "invalid_merge"},
+	{game.ErrMergeNotAdjacent, "invalid_merge"},
+	{ErrGameNotOver, "game_not_over"},
+	{ErrScoreSubmitted, "score_submitted"},
+	{ErrNoScore, "no_score"},
+	{ErrLeaderboardDisabled, "leaderboard_unavailable"},
+	{ErrSavesDisabled, "saves_unavailable"},
+	{ErrNotSolo, "not_solo"},
+	{db.ErrSavedGameNotFound, "save_not_found"},
+}
+
+// errorCode returns the code a client gets for an error
+func errorCode(err error) string {
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	for _, known := range errorCodes {
+		if errors.Is(err, known.err) {
+			return known.code
+		}
+	}
+	return internalError
+}
+
+// Request is a message being handled for a client
+type Request struct {
+	Hub    *Hub
+	Client *Client
+	Msg    *Message
+}
+
+// PlayerID returns the ID of the player who sent the message
+func (r *Request) PlayerID() string {
+	return r.Msg.SenderID
+}
+
+// State returns the state of the room the message was sent to
+func (r *Request) State() *RoomState {
+	return r.Hub.GetRoomState(r.Msg.RoomID)
+}
+
+// Reply sends a message to the client that sent the request
+func (r *Request) Reply(msgType string, payload interface{}) error {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	r.Hub.Unicast(r.Client, &Message{
+		Type:          msgType,
+		Payload:       payloadJSON,
+		SenderID:      "server",
+		CorrelationID: r.Msg.CorrelationID,
+	})
+	return nil
+}
+
+// Broadcast sends a message to every client in the request's room
+func (r *Request) Broadcast(msgType string, payload interface{}) error {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	r.Hub.BroadcastToRoom(r.Msg.RoomID, &Message{
+		Type:     msgType,
+		Payload:  payloadJSON,
+		SenderID: "server",
+	})
+	return nil
+}
+
+// HandlerFunc handles a message from a client
+type HandlerFunc func(req *Request) error
+
+// Middleware wraps a handler with checks that run before it
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// validator is implemented by payloads that check their own fields
+type validator interface {
+	Validate() error
+}
+
+// noPayload is the payload of messages that carry none
+type noPayload struct{}
+
+// handlers maps message types to their handlers, filled by the handle_*.go files
+var handlers = make(map[string]HandlerFunc)
+
+// register adds the handler of a message type. The payload is decoded into P and validated
+// before the handler runs, after the middleware in the order given.
+func register[P any](msgType string, handle func(req *Request, payload P) error, middleware ...Middleware) {
+	if _, ok := handlers[msgType]; ok {
+		panic(fmt.Sprintf("duplicate handler for %s", msgType))
+	}
+
+	handler := func(req *Request) error {
+		var payload P
+		if len(req.Msg.Payload) > 0 && string(req.Msg.Payload) != "null" {
+			if err := json.Unmarshal(req.Msg.Payload, &payload); err != nil {
+				return withCode("invalid_payload", fmt.Errorf("%s payload: %w", msgType, err))
+			}
+		}
+		if v, ok := any(payload).(validator); ok {
+			if err := v.Validate(); err != nil {
+				return withCode("invalid_payload", fmt.Errorf("%s payload: %w", msgType, err))
+			}
+		}
+		return handle(req, payload)
+	}
+
+	// The first middleware runs first
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	handlers[msgType] = handler
+}
+
+// codedError is an error sent to clients with a code of its own rather than the one of the error it wraps
+type codedError struct {
+	code string
+	err  error
+}
+
+func (e *codedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *codedError) Unwrap() error {
+	return e.err
+}
+
+// withCode marks an error to be sent to the client with a code
+func withCode(code string, err error) error {
+	return &codedError{code: code, err: err}
+}
+
+// dispatch handles a message from a client with the handler registered for its type.
+// Failures, including messages of a type without a handler, are sent back to the client
+// as an error with the message's correlation ID.
+func (h *Hub) dispatch(c *Client, msg *Message) {
+	handler, ok := handlers[msg.Type]
+	if !ok {
+		log.Printf("Rejected %s from client %s: %v", msg.Type, c.ID, ErrUnknownType)
+		h.sendError(c, "unknown_type", fmt.Sprintf("%v: %s", ErrUnknownType, msg.Type), msg.CorrelationID)
+		return
+	}
+
+	err := handler(&Request{Hub: h, Client: c, Msg: msg})
+	if err == nil {
+		return
+	}
+
+	log.Printf("Error handling %s from player %s: %v", msg.Type, msg.SenderID, err)
+
+	code := errorCode(err)
+	message := err.Error()
+	if code == internalError {
+		message = fmt.Sprintf("the server couldn't handle %s", msg.Type)
+	}
+	h.sendError(c, code, message, msg.CorrelationID)
+}
+
+// authenticated rejects messages sent on behalf of another player
+func authenticated(next HandlerFunc) HandlerFunc {
+	return func(req *Request) error {
+		if req.Client.PlayerID == "" || req.Msg.SenderID != req.Client.PlayerID {
+			return withCode("unauthorized", ErrUnauthorized)
+		}
+		return next(req)
+	}
+}
+
+// inRoom rejects messages from clients outside a room, or sent to a room the client isn't in
+func inRoom(next HandlerFunc) HandlerFunc {
+	return func(req *Request) error {
+		if req.Client.RoomID == "" {
+			return withCode("no_room", ErrNoRoom)
+		}
+		if req.Msg.RoomID != req.Client.RoomID {
+			return withCode("unknown_room", ErrUnknownRoom)
+		}
+		return next(req)
+	}
+}
+
+// inPhase rejects game actions while the game is paused or outside the phases that allow them
+func inPhase(next HandlerFunc) HandlerFunc {
+	return func(req *Request) error {
+		if err := req.Hub.checkPaused(req.Msg.RoomID, req.Msg.Type); err != nil {
+			return withCode("game_paused", err)
+		}
+		if err := req.Hub.checkPhase(req.Msg.RoomID, req.Msg.Type); err != nil {
+			return withCode("wrong_phase", err)
+		}
+		return next(req)
+	}
+}
+
+// rateWindow counts a client's messages of one type in the current window
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// rateLimited allows a client at most limit messages of a type per period
+func rateLimited(limit int, period time.Duration) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(req *Request) error {
+			c := req.Client
+			if c.limits == nil {
+				c.limits = make(map[string]*rateWindow)
+			}
+
+			now := time.Now()
+			window, ok := c.limits[req.Msg.Type]
+			if !ok || now.Sub(window.start) >= period {
+				window = &rateWindow{start: now}
+				c.limits[req.Msg.Type] = window
+			}
+			window.count++
+			if window.count > limit {
+				return withCode("rate_limited", ErrRateLimited)
+			}
+			return next(req)
+		}
+	}
+}
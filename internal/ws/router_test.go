@@ -0,0 +1,385 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"realtime-game-backend/internal/game"
+)
+
+func TestMain(m *testing.M) {
+	// The hub logs every message it handles
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// newTestHub starts a hub without persistence that stops when the test ends
+func newTestHub(t *testing.T) *Hub {
+	t.Helper()
+
+	hub := NewHub(nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go hub.Run(ctx)
+	return hub
+}
+
+// joinTestClient registers a client without a socket and drains the messages sent on registration
+func joinTestClient(t *testing.T, hub *Hub, playerID, roomID string) *Client {
+	t.Helper()
+
+	client := &Client{
+		ID:       "client-" + playerID,
+		Send:     make(chan []byte, 256),
+		Hub:      hub,
+		PlayerID: playerID,
+		RoomID:   roomID,
+	}
+	hub.Register <- client
+
+	expectMessage(t, client, "session")
+	if roomID != "" {
+		expectMessage(t, client, "phase_changed")
+	}
+	return client
+}
+
+// sendMessage dispatches a message from a client as its readPump would
+func sendMessage(t *testing.T, client *Client, msgType string, payload interface{}) {
+	t.Helper()
+
+	var payloadJSON json.RawMessage
+	if payload != nil {
+		var err error
+		if payloadJSON, err = json.Marshal(payload); err != nil {
+			t.Fatalf("marshaling %s payload: %v", msgType, err)
+		}
+	}
+
+	client.Hub.dispatch(client, &Message{
+		Type:     msgType,
+		Payload:  payloadJSON,
+		RoomID:   client.RoomID,
+		SenderID: client.PlayerID,
+	})
+}
+
+// expectMessage waits for a message of a type sent to a client, skipping other messages
+func expectMessage(t *testing.T, client *Client, msgType string) *Message {
+	t.Helper()
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case data := <-client.Send:
+			var msg Message
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Fatalf("unmarshaling message: %v", err)
+			}
+			if msg.Type == msgType {
+				return &msg
+			}
+		case <-timeout:
+			t.Fatalf("client %s got no %s message", client.ID, msgType)
+		}
+	}
+}
+
+// expectNoMessage checks that a client gets no message of a type within a short wait
+func expectNoMessage(t *testing.T, client *Client, msgType string) {
+	t.Helper()
+
+	timeout := time.After(100 * time.Millisecond)
+	for {
+		select {
+		case data := <-client.Send:
+			var msg Message
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Fatalf("unmarshaling message: %v", err)
+			}
+			if msg.Type == msgType {
+				t.Fatalf("client %s got an unexpected %s message: %s", client.ID, msgType, msg.Payload)
+			}
+		case <-timeout:
+			return
+		}
+	}
+}
+
+// errorPayload is the payload of error
+type errorPayload struct {
+	Code          string `json:"code"`
+	Message       string `json:"message"`
+	CorrelationID string `json:"correlationId"`
+}
+
+// expectError waits for an error message and checks its code
+func expectError(t *testing.T, client *Client, code string) errorPayload {
+	t.Helper()
+
+	var payload errorPayload
+	decodePayload(t, expectMessage(t, client, "error"), &payload)
+	if payload.Code != code {
+		t.Fatalf("got error %q (%s), want %q", payload.Code, payload.Message, code)
+	}
+	return payload
+}
+
+// decodePayload unmarshals a message's payload
+func decodePayload(t *testing.T, msg *Message, v interface{}) {
+	t.Helper()
+
+	if err := json.Unmarshal(msg.Payload, v); err != nil {
+		t.Fatalf("unmarshaling %s payload: %v", msg.Type, err)
+	}
+}
+
+// setGold sets a player's gold
+func setGold(hub *Hub, roomID, playerID string, gold int) {
+	state := hub.GetRoomState(roomID)
+	state.Mutex.Lock()
+	state.Player(playerID).Gold = gold
+	state.Mutex.Unlock()
+}
+
+// setPhase moves a room straight to a phase
+func setPhase(hub *Hub, roomID, phase string) {
+	state := hub.GetRoomState(roomID)
+	state.Mutex.Lock()
+	state.Phase = phase
+	state.Mutex.Unlock()
+}
+
+func TestDispatchRelaysWhitelistedTypes(t *testing.T) {
+	hub := newTestHub(t)
+	alice := joinTestClient(t, hub, "alice", "room")
+	bob := joinTestClient(t, hub, "bob", "room")
+
+	sendMessage(t, alice, "ready", nil)
+
+	msg := expectMessage(t, bob, "ready")
+	if msg.SenderID != "alice" {
+		t.Fatalf("got sender %q, want alice", msg.SenderID)
+	}
+}
+
+func TestDispatchRejectsForgedServerEvents(t *testing.T) {
+	hub := newTestHub(t)
+	alice := joinTestClient(t, hub, "alice", "room")
+	bob := joinTestClient(t, hub, "bob", "room")
+
+	alice.Hub.dispatch(alice, &Message{
+		Type:     "game_over",
+		Payload:  json.RawMessage(`{"won":true}`),
+		RoomID:   "room",
+		SenderID: "server",
+	})
+	expectError(t, alice, "unknown_type")
+	expectNoMessage(t, bob, "game_over")
+}
+
+func TestDispatchRejectsInvalidPayload(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	client.Hub.dispatch(client, &Message{
+		Type:     "load_game",
+		Payload:  json.RawMessage(`"not an object"`),
+		RoomID:   "room",
+		SenderID: "alice",
+	})
+	expectError(t, client, "invalid_payload")
+}
+
+func TestDispatchEchoesCorrelationID(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	client.Hub.dispatch(client, &Message{
+		Type:          "start_wave",
+		RoomID:        "room",
+		SenderID:      "alice",
+		CorrelationID: "42",
+	})
+	if payload := expectError(t, client, "wrong_phase"); payload.CorrelationID != "42" {
+		t.Fatalf("got correlation ID %q, want 42", payload.CorrelationID)
+	}
+}
+
+func TestDispatchHidesInternalErrors(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	handlers["test_internal"] = func(req *Request) error {
+		return errors.New("connection refused")
+	}
+	t.Cleanup(func() { delete(handlers, "test_internal") })
+
+	sendMessage(t, client, "test_internal", nil)
+	if payload := expectError(t, client, internalError); payload.Message == "connection refused" {
+		t.Fatal("internal error details were sent to the client")
+	}
+}
+
+func TestDispatchRejectsRelayingToOtherRooms(t *testing.T) {
+	hub := newTestHub(t)
+	alice := joinTestClient(t, hub, "alice", "room")
+	bob := joinTestClient(t, hub, "bob", "other")
+
+	alice.Hub.dispatch(alice, &Message{
+		Type:     "ready",
+		RoomID:   "other",
+		SenderID: "alice",
+	})
+	expectError(t, alice, "unknown_room")
+	expectNoMessage(t, bob, "ready")
+}
+
+// roomlessTypes are the message types clients can send from outside a room
+var roomlessTypes = map[string]bool{"list_saves": true, "load_game": true}
+
+// registeredTypes returns every message type with a handler, sorted
+func registeredTypes() []string {
+	types := make([]string, 0, len(handlers))
+	for msgType := range handlers {
+		types = append(types, msgType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func TestEveryHandlerIsAuthenticated(t *testing.T) {
+	hub := newTestHub(t)
+
+	// Outside a room, so the check has to run before inRoom
+	client := joinTestClient(t, hub, "alice", "")
+
+	for _, msgType := range registeredTypes() {
+		t.Run(msgType, func(t *testing.T) {
+			client.Hub.dispatch(client, &Message{Type: msgType, SenderID: "bob"})
+			expectError(t, client, "unauthorized")
+		})
+	}
+}
+
+func TestRoomHandlersRejectClientsOutsideRooms(t *testing.T) {
+	hub := newTestHub(t)
+	outside := joinTestClient(t, hub, "alice", "")
+	inside := joinTestClient(t, hub, "bob", "room")
+
+	for _, msgType := range registeredTypes() {
+		if roomlessTypes[msgType] {
+			continue
+		}
+		t.Run(msgType, func(t *testing.T) {
+			sendMessage(t, outside, msgType, nil)
+			expectError(t, outside, "no_room")
+
+			inside.Hub.dispatch(inside, &Message{Type: msgType, RoomID: "other", SenderID: "bob"})
+			expectError(t, inside, "unknown_room")
+		})
+	}
+}
+
+func TestPhasedHandlersCheckThePhase(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	phases := []string{game.SetupPhase, game.CardsPhase, game.TowersPhase, game.CombatPhase, game.EndPhase}
+	for _, msgType := range registeredTypes() {
+		if !game.PhaseRestricted(msgType) {
+			continue
+		}
+		t.Run(msgType, func(t *testing.T) {
+			for _, phase := range phases {
+				if game.CheckAction(phase, msgType) == nil {
+					continue
+				}
+				setPhase(hub, "room", phase)
+				sendMessage(t, client, msgType, nil)
+				expectError(t, client, "wrong_phase")
+			}
+		})
+	}
+}
+
+func TestPhasedHandlersRejectWhilePaused(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	state := hub.GetRoomState("room")
+	state.Mutex.Lock()
+	state.Paused = true
+	state.Mutex.Unlock()
+
+	for _, msgType := range registeredTypes() {
+		if !game.PhaseRestricted(msgType) {
+			continue
+		}
+		t.Run(msgType, func(t *testing.T) {
+			sendMessage(t, client, msgType, nil)
+			expectError(t, client, "game_paused")
+		})
+	}
+}
+
+func TestRateLimitedRunsLast(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "")
+
+	// Rejected messages don't count against the limit
+	for i := 0; i < 5; i++ {
+		client.Hub.dispatch(client, &Message{Type: "list_saves", SenderID: "bob"})
+		expectError(t, client, "unauthorized")
+	}
+
+	// list_saves allows two messages a second
+	for i := 0; i < 2; i++ {
+		sendMessage(t, client, "list_saves", nil)
+		expectError(t, client, "saves_unavailable")
+	}
+	sendMessage(t, client, "list_saves", nil)
+	expectError(t, client, "rate_limited")
+}
+
+func TestRateLimited(t *testing.T) {
+	hub := newTestHub(t)
+	client := joinTestClient(t, hub, "alice", "room")
+
+	called := 0
+	handler := rateLimited(2, time.Minute)(func(req *Request) error {
+		called++
+		return nil
+	})
+
+	req := &Request{Hub: hub, Client: client, Msg: &Message{Type: "test"}}
+	for i := 0; i < 3; i++ {
+		err := handler(req)
+		if i < 2 && err != nil {
+			t.Fatalf("message %d: unexpected error %v", i+1, err)
+		}
+		if i == 2 && err == nil {
+			t.Fatal("third message wasn't rate limited")
+		}
+	}
+	if called != 2 {
+		t.Fatalf("handler called %d times, want 2", called)
+	}
+}
+
+func TestEveryPhasedActionHasAHandler(t *testing.T) {
+	for _, phase := range []string{game.SetupPhase, game.CardsPhase, game.TowersPhase, game.CombatPhase, game.EndPhase} {
+		for _, action := range game.AllowedActions(phase) {
+			if _, ok := handlers[action]; !ok {
+				t.Errorf("no handler for %s, allowed during %s", action, phase)
+			}
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"realtime-game-backend/internal/game"
+	"realtime-game-backend/internal/models"
+)
+
+// Error definitions
+var (
+	ErrSavesDisabled = errors.New("saved games need persistence")
+	ErrNotSolo       = errors.New("only solo games can be saved")
+)
+
+// snapshot captures the room's game, including the poker hand of the player it's for.
+// The caller must hold the room mutex.
+func (r *RoomState) snapshot(playerID string) models.GameState {
+	if playerID != "" {
+		r.Player(playerID)
+	}
+
+	players := make(map[string]*models.PlayerState, len(r.Players))
+	for id, player := range r.Players {
+		saved := *player
+		saved.HandPlayed = r.handsPlayed[id]
+		if hand, ok := r.hands[id]; ok && id == playerID && hand.Round == r.Round {
+			saved.Cards = append([]models.Card(nil), hand.Cards...)
+			saved.Deck = append([]models.Card(nil), hand.Deck...)
+			saved.DrawCount = hand.DrawCount
+		}
+		players[id] = &saved
+	}
+
+	runStats := make([]models.TowerStats, 0, len(r.RunStats))
+	for _, stats := range r.RunStats {
+		runStats = append(runStats, *stats)
+	}
+
+	return models.GameState{
+		SessionID:  r.SessionID,
+		RoomID:     r.ID,
+		Round:      r.Round,
+		Phase:      r.Phase,
+		Players:    players,
+		UpdatedAt:  time.Now().UnixNano() / int64(time.Millisecond),
+		Status:     "active",
+		MapID:      r.Map.ID,
+		Mode:       r.Mode,
+		RunType:    r.RunType,
+		Seed:       r.Seed,
+		WaveLevel:  r.WaveLevel,
+		BaseMode:   r.BaseMode,
+		BaseHealth: r.BaseHealth,
+		Towers:     append([]models.Tower(nil), r.Towers...),
+		RunStats:   runStats,
+		Scouting:   copyCounts(r.Scouting),
+		PausesUsed: copyCounts(r.PausesUsed),
+		Adaptive:   r.Director != nil,
+		Assisted:   r.Assisted,
+	}
+}
+
+// restoreRoomState rebuilds a room from a saved game.
+// Upcoming waves are generated again from the saved seed, and the adaptive director starts with a fresh history.
+func restoreRoomState(roomID string, saved *models.GameState) (*RoomState, error) {
+	gameMap, err := game.GetMap(saved.MapID)
+	if err != nil {
+		return nil, err
+	}
+	if err := game.CheckAction(saved.Phase, "save_game"); err != nil {
+		return nil, err
+	}
+
+	state := NewRoomState(roomID)
+	state.Map = gameMap
+	state.Mode = saved.Mode
+	state.SetRunType(saved.RunType, saved.Seed)
+	state.WaveLevel = saved.WaveLevel
+	state.BaseMode = saved.BaseMode
+	state.BaseHealth = saved.BaseHealth
+	state.Towers = append(state.Towers, saved.Towers...)
+	state.SessionID = saved.SessionID
+	state.Phase = saved.Phase
+	state.Round = saved.Round
+	state.Assisted = saved.Assisted
+	if saved.Adaptive {
+		state.Director = game.NewDirector()
+	}
+
+	for id, player := range saved.Players {
+		restored := *player
+		if restored.HandPlayed {
+			state.handsPlayed[id] = true
+		}
+		if restored.DrawCount > 0 {
+			state.hands[id] = &pokerHand{
+				Round:     saved.Round,
+				Cards:     restored.Cards,
+				Deck:      restored.Deck,
+				DrawCount: restored.DrawCount,
+			}
+		}
+
+		// Hands are kept apart from the players' public state
+		restored.Cards = nil
+		restored.Deck = nil
+		restored.DrawCount = 0
+		restored.HandPlayed = false
+		state.Players[id] = &restored
+	}
+	for _, stats := range saved.RunStats {
+		stats := stats
+		state.RunStats[stats.TowerID] = &stats
+	}
+	for id, level := range saved.Scouting {
+		state.Scouting[id] = level
+	}
+	for id, used := range saved.PausesUsed {
+		state.PausesUsed[id] = used
+	}
+
+	return state, nil
+}
+
+// copyCounts copies a map of counts by player ID
+func copyCounts(counts map[string]int) map[string]int {
+	copied := make(map[string]int, len(counts))
+	for id, count := range counts {
+		copied[id] = count
+	}
+	return copied
+}
+
+// saveGame saves a client's solo game to Postgres and returns the ID of the save
+func (h *Hub) saveGame(client *Client) (string, models.GameState, error) {
+	if h.Postgres == nil {
+		return "", models.GameState{}, ErrSavesDisabled
+	}
+	if len(h.roomPlayerIDs(client.RoomID)) > 1 {
+		return "", models.GameState{}, ErrNotSolo
+	}
+
+	state := h.GetRoomState(client.RoomID)
+	state.Mutex.Lock()
+	if state.GameOver {
+		state.Mutex.Unlock()
+		return "", models.GameState{}, ErrGameOver
+	}
+	snapshot := state.snapshot(client.PlayerID)
+	state.Mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	saveID := generateID()
+	if err := h.Postgres.SaveGame(ctx, saveID, client.PlayerID, snapshot); err != nil {
+		return "", models.GameState{}, err
+	}
+	return saveID, snapshot, nil
+}
+
+// loadGame restores a client's saved game into a new room and moves the client there
+func (h *Hub) loadGame(client *Client, saveID string) (*RoomState, error) {
+	if h.Postgres == nil {
+		return nil, ErrSavesDisabled
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	saved, err := h.Postgres.LoadGame(ctx, saveID, client.PlayerID)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := restoreRoomState("save-"+generateID(), saved)
+	if err != nil {
+		return nil, err
+	}
+
+	h.Mutex.Lock()
+	h.States[state.ID] = state
+	h.Mutex.Unlock()
+
+	// Move the player to the restored room
+	h.leaveRoom(client)
+	h.moveToRoom(client, state.ID)
+	if h.Redis != nil {
+		if err := h.Redis.AddPlayerToRoom(ctx, state.ID, client.PlayerID); err != nil {
+			log.Printf("Error adding player %s to room %s: %v", client.PlayerID, state.ID, err)
+		}
+	}
+
+	return state, nil
+}
+
+// savedGames returns a client's saved games
+func (h *Hub) savedGames(client *Client) ([]models.SavedGame, error) {
+	if h.Postgres == nil {
+		return nil, ErrSavesDisabled
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return h.Postgres.ListSavedGames(ctx, client.PlayerID)
+}
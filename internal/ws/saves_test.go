@@ -0,0 +1,194 @@
+package ws
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"realtime-game-backend/internal/game"
+	"realtime-game-backend/internal/models"
+)
+
+// savedRoom builds a room in the middle of a game, with a hand dealt to alice and bob
+func savedRoom(t *testing.T) *RoomState {
+	t.Helper()
+
+	state := NewRoomState("room")
+	state.Mode = game.MazeMode
+	state.SetRunType(game.EndlessRun, 42)
+	state.Phase = game.CardsPhase
+	state.Round = 3
+	state.WaveLevel = 2
+	state.BaseMode = SharedBase
+	state.BaseHealth = 14
+	state.SessionID = "session"
+	state.Director = game.NewDirector()
+	state.Assisted = true
+
+	alice := state.Player("alice")
+	alice.Gold, alice.Score = 80, 120
+	bob := state.Player("bob")
+	bob.Gold, bob.Health = 30, 11
+
+	// Alice is between draws with a card held; bob has already played a hand
+	state.hand("alice").draw()
+	state.hand("alice").setHeld(state.hands["alice"].Cards[1].ID, true)
+	state.hand("alice").draw()
+	state.hand("bob").draw()
+	state.handsPlayed["bob"] = true
+
+	tower, err := game.CreateTower("alice", game.BasicTower, 150, 150)
+	if err != nil {
+		t.Fatalf("creating tower: %v", err)
+	}
+	state.Towers = append(state.Towers, tower)
+	state.RunStats[tower.ID] = &models.TowerStats{TowerID: tower.ID, TowerType: tower.Type, PlayerID: "alice", Kills: 7, DamageDealt: 640}
+
+	state.Scouting["alice"] = 5
+	state.PausesUsed["alice"] = 1
+	state.PausesUsed["bob"] = 2
+	return state
+}
+
+// roundTrip saves a room for a player and restores it, going through JSON like a save stored in Postgres
+func roundTrip(t *testing.T, state *RoomState, playerID string) *RoomState {
+	t.Helper()
+
+	data, err := json.Marshal(state.snapshot(playerID))
+	if err != nil {
+		t.Fatalf("marshaling snapshot: %v", err)
+	}
+	var saved models.GameState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("unmarshaling snapshot: %v", err)
+	}
+
+	restored, err := restoreRoomState("restored", &saved)
+	if err != nil {
+		t.Fatalf("restoring room: %v", err)
+	}
+	return restored
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	state := savedRoom(t)
+	restored := roundTrip(t, state, "alice")
+
+	if restored.ID != "restored" {
+		t.Errorf("got room ID %q, want restored", restored.ID)
+	}
+	if restored.Map.ID != state.Map.ID || restored.Mode != state.Mode {
+		t.Errorf("got map %s in %s mode, want %s in %s mode", restored.Map.ID, restored.Mode, state.Map.ID, state.Mode)
+	}
+	if restored.RunType != state.RunType || restored.Seed != state.Seed {
+		t.Errorf("got %s run with seed %d, want %s with seed %d", restored.RunType, restored.Seed, state.RunType, state.Seed)
+	}
+	if restored.Phase != state.Phase || restored.Round != state.Round || restored.WaveLevel != state.WaveLevel {
+		t.Errorf("got %s phase, round %d, wave %d", restored.Phase, restored.Round, restored.WaveLevel)
+	}
+	if restored.BaseMode != state.BaseMode || restored.BaseHealth != state.BaseHealth {
+		t.Errorf("got %s base with %d health", restored.BaseMode, restored.BaseHealth)
+	}
+	if restored.SessionID != state.SessionID || restored.Director == nil || !restored.Assisted {
+		t.Errorf("got session %q, director %v, assisted %v", restored.SessionID, restored.Director != nil, restored.Assisted)
+	}
+
+	if !reflect.DeepEqual(restored.Towers, state.Towers) {
+		t.Errorf("got towers %+v, want %+v", restored.Towers, state.Towers)
+	}
+	if !reflect.DeepEqual(restored.RunStats, state.RunStats) {
+		t.Errorf("got run stats %+v, want %+v", restored.RunStats, state.RunStats)
+	}
+	if !reflect.DeepEqual(restored.Scouting, state.Scouting) {
+		t.Errorf("got scouting %v, want %v", restored.Scouting, state.Scouting)
+	}
+	if !reflect.DeepEqual(restored.PausesUsed, state.PausesUsed) {
+		t.Errorf("got pauses used %v, want %v", restored.PausesUsed, state.PausesUsed)
+	}
+	if !reflect.DeepEqual(restored.Players, state.Players) {
+		t.Errorf("got players %+v, want %+v", restored.Players, state.Players)
+	}
+}
+
+func TestSnapshotRoundTripKeepsHandsPrivate(t *testing.T) {
+	state := savedRoom(t)
+	restored := roundTrip(t, state, "alice")
+
+	// The saving player's hand comes back in the room, not in their public state
+	hand, ok := restored.hands["alice"]
+	if !ok {
+		t.Fatal("alice's hand wasn't restored")
+	}
+	want := state.hands["alice"]
+	if hand.Round != want.Round || hand.DrawCount != want.DrawCount {
+		t.Errorf("got hand of round %d after %d draws, want round %d after %d", hand.Round, hand.DrawCount, want.Round, want.DrawCount)
+	}
+	if !reflect.DeepEqual(hand.Cards, want.Cards) || !reflect.DeepEqual(hand.Deck, want.Deck) {
+		t.Error("alice's cards or deck changed in the round trip")
+	}
+	if player := restored.Players["alice"]; len(player.Cards) != 0 || len(player.Deck) != 0 || player.DrawCount != 0 {
+		t.Error("alice's hand was left in the public player state")
+	}
+
+	// Other players' hands are never saved, but who played their hand is
+	if _, ok := restored.hands["bob"]; ok {
+		t.Error("bob's hand was saved in alice's game")
+	}
+	if !restored.handsPlayed["bob"] || restored.handsPlayed["alice"] {
+		t.Errorf("got hands played %v, want only bob", restored.handsPlayed)
+	}
+}
+
+func TestSnapshotSkipsHandsFromEarlierRounds(t *testing.T) {
+	state := savedRoom(t)
+	state.Round++
+
+	if _, ok := roundTrip(t, state, "alice").hands["alice"]; ok {
+		t.Fatal("restored a hand left over from an earlier round")
+	}
+}
+
+func TestMoveToRoom(t *testing.T) {
+	hub := newTestHub(t)
+	alice := joinTestClient(t, hub, "alice", "room")
+	old := hub.GetRoomState("room")
+
+	restored := NewRoomState("restored")
+	hub.Mutex.Lock()
+	hub.States[restored.ID] = restored
+	hub.Mutex.Unlock()
+
+	hub.moveToRoom(alice, restored.ID)
+	expectMessage(t, alice, "map_selected")
+	expectMessage(t, alice, "phase_changed")
+
+	hub.Mutex.RLock()
+	_, inOld := hub.Rooms["room"][alice.ID]
+	_, inNew := hub.Rooms[restored.ID][alice.ID]
+	hub.Mutex.RUnlock()
+	if inOld || !inNew {
+		t.Fatalf("got alice in the old room %v and in the new room %v", inOld, inNew)
+	}
+	if !old.isClosed() {
+		t.Fatal("the empty room's state was kept")
+	}
+
+	// Moving rooms keeps the session
+	expectNoMessage(t, alice, "session")
+}
+
+func TestMoveToRoomAfterDisconnect(t *testing.T) {
+	hub := newTestHub(t)
+	alice := joinTestClient(t, hub, "alice", "room")
+
+	hub.Unregister <- alice
+	hub.moveToRoom(alice, "restored")
+
+	// The hub doesn't take the dropped client back
+	hub.Mutex.RLock()
+	_, registered := hub.Clients[alice.ID]
+	hub.Mutex.RUnlock()
+	if registered {
+		t.Fatal("a disconnected client was registered again")
+	}
+}